@@ -2,7 +2,16 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"log/slog"
+	"time"
+)
+
+type ACMEProvider string
+
+const (
+	ACMEProviderLetsEncrypt ACMEProvider = "1"
+	ACMEProviderDigicert    ACMEProvider = "2"
 )
 
 type RequestACMECertificateParameters struct {
@@ -22,14 +31,14 @@ func (c *Client) RegisterLetsEncryptAccount(email *string) (*LoadMasterResponse,
 	slog.Debug("Register Lets Encrypt account")
 	payload := struct {
 		*LoadMasterRequest
-		Email *string `json:"email,omitempty"`
-		Type  string  `json:"acmetype"`
+		Email *string      `json:"email,omitempty"`
+		Type  ACMEProvider `json:"acmetype"`
 	}{
 		&LoadMasterRequest{
 			Command: "registeracmeaccount",
 		},
 		email,
-		"1",
+		ACMEProviderLetsEncrypt,
 	}
 
 	http, err := c.newRequest(payload)
@@ -87,14 +96,14 @@ func (c *Client) SetDigicertKeyId(key_id string) (*LoadMasterResponse, error) {
 	slog.Debug("Setting Digicert key ID")
 	payload := struct {
 		*LoadMasterRequest
-		KeyId string `json:"kid"`
-		Type  string `json:"acmetype"`
+		KeyId string       `json:"kid"`
+		Type  ACMEProvider `json:"acmetype"`
 	}{
 		&LoadMasterRequest{
 			Command: "setacmekid",
 		},
 		key_id,
-		"2",
+		ACMEProviderDigicert,
 	}
 	http, err := c.newRequest(payload)
 	if err != nil {
@@ -119,14 +128,14 @@ func (c *Client) SetDigicertHMAC(hmac string) (*LoadMasterResponse, error) {
 	slog.Debug("Setting Digicert HMAC")
 	payload := struct {
 		*LoadMasterRequest
-		Hmac string `json:"hmac"`
-		Type string `json:"acmetype"`
+		Hmac string       `json:"hmac"`
+		Type ACMEProvider `json:"acmetype"`
 	}{
 		&LoadMasterRequest{
 			Command: "setacmehmac",
 		},
 		hmac,
-		"2",
+		ACMEProviderDigicert,
 	}
 	http, err := c.newRequest(payload)
 	if err != nil {
@@ -147,15 +156,15 @@ func (c *Client) SetDigicertHMAC(hmac string) (*LoadMasterResponse, error) {
 	return response, nil
 }
 
-func (c *Client) RequestACMECertificate(name string, common_name string, vs_identifier string, acme_type string, params *RequestACMECertificateParameters) (*LoadMasterResponse, error) {
+func (c *Client) RequestACMECertificate(name string, common_name string, vs_identifier string, acme_type ACMEProvider, params *RequestACMECertificateParameters) (*LoadMasterResponse, error) {
 	slog.Debug("Request ACME Certificate", "name", name, "common_name", common_name, "vs_identifier", vs_identifier, "acme_type", acme_type)
 	payload := struct {
 		*LoadMasterRequest
 		*RequestACMECertificateParameters
-		Name       string `json:"cert"`
-		CommonName string `json:"cn"`
-		VS         string `json:"vid"`
-		AcmeType   string `json:"acmetype"`
+		Name       string       `json:"cert"`
+		CommonName string       `json:"cn"`
+		VS         string       `json:"vid"`
+		AcmeType   ACMEProvider `json:"acmetype"`
 	}{
 		LoadMasterRequest: &LoadMasterRequest{
 			Command: "addacmecert",
@@ -175,12 +184,12 @@ func (c *Client) RequestACMECertificate(name string, common_name string, vs_iden
 	return response, nil
 }
 
-func (c *Client) DeleteACMECertificate(name string, acme_type string) (*LoadMasterResponse, error) {
+func (c *Client) DeleteACMECertificate(name string, acme_type ACMEProvider) (*LoadMasterResponse, error) {
 	slog.Debug("Delete ACME Certificate", "name", name, "acme_type", acme_type)
 	payload := struct {
 		*LoadMasterRequest
-		Name     string `json:"cert"`
-		AcmeType string `json:"acmetype"`
+		Name     string       `json:"cert"`
+		AcmeType ACMEProvider `json:"acmetype"`
 	}{
 		LoadMasterRequest: &LoadMasterRequest{
 			Command: "delacmecert",
@@ -195,3 +204,254 @@ func (c *Client) DeleteACMECertificate(name string, acme_type string) (*LoadMast
 
 	return response, nil
 }
+
+type ACMECertificate struct {
+	Name           string `json:"name"`
+	CommonName     string `json:"cn,omitempty"`
+	VirtualService string `json:"vid,omitempty"`
+	Status         string `json:"status,omitempty"`
+	Expiry         string `json:"expiry,omitempty"`
+}
+
+type ListACMECertResponse struct {
+	*LoadMasterResponse
+	Cert []ACMECertificate `json:"cert"`
+}
+
+type ShowACMECertResponse struct {
+	*LoadMasterResponse
+	Cert ACMECertificate `json:"cert"`
+}
+
+type ACMEAccountResponse struct {
+	*LoadMasterResponse
+	AccountID    string `json:"AccountID,omitempty"`
+	Email        string `json:"Email,omitempty"`
+	Status       string `json:"Status,omitempty"`
+	DirectoryURL string `json:"DirectoryURL,omitempty"`
+}
+
+type ACMERenewPeriodResponse struct {
+	*LoadMasterResponse
+	// RenewPeriod is the number of days before expiry at which certificates are renewed.
+	RenewPeriod int32 `json:"renewperiod"`
+}
+
+type ACMEDirectoryURLResponse struct {
+	*LoadMasterResponse
+	DirectoryURL string `json:"directoryurl"`
+}
+
+var acmeExpiryLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "Jan _2 15:04:05 2006 MST", "2006-01-02"}
+
+// ExpiresAt parses the expiry date reported by the LoadMaster.
+func (a ACMECertificate) ExpiresAt() (time.Time, error) {
+	for _, layout := range acmeExpiryLayouts {
+		expiry, err := time.Parse(layout, a.Expiry)
+		if err == nil {
+			return expiry, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unknown expiry format %q of certificate %s", a.Expiry, a.Name)
+}
+
+func (c *Client) ListACMECertificate(acme_type ACMEProvider) (*ListACMECertResponse, error) {
+	slog.Debug("List ACME Certificates", "acme_type", acme_type)
+	payload := struct {
+		*LoadMasterRequest
+		AcmeType ACMEProvider `json:"acmetype"`
+	}{
+		LoadMasterRequest: &LoadMasterRequest{
+			Command: "listacmecert",
+		},
+		AcmeType: acme_type,
+	}
+
+	response, err := sendRequest(c, payload, ListACMECertResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (c *Client) ShowACMECertificate(name string, acme_type ACMEProvider) (*ShowACMECertResponse, error) {
+	slog.Debug("Show ACME Certificate", "name", name, "acme_type", acme_type)
+	payload := struct {
+		*LoadMasterRequest
+		Name     string       `json:"cert"`
+		AcmeType ACMEProvider `json:"acmetype"`
+	}{
+		LoadMasterRequest: &LoadMasterRequest{
+			Command: "getacmecert",
+		},
+		Name:     name,
+		AcmeType: acme_type,
+	}
+
+	response, err := sendRequest(c, payload, ShowACMECertResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// RenewACMECertificate forces the renewal of the certificate, regardless of the renew period.
+func (c *Client) RenewACMECertificate(name string, acme_type ACMEProvider) (*LoadMasterResponse, error) {
+	slog.Debug("Renew ACME Certificate", "name", name, "acme_type", acme_type)
+	payload := struct {
+		*LoadMasterRequest
+		Name     string       `json:"cert"`
+		AcmeType ACMEProvider `json:"acmetype"`
+	}{
+		LoadMasterRequest: &LoadMasterRequest{
+			Command: "renewacmecert",
+		},
+		Name:     name,
+		AcmeType: acme_type,
+	}
+
+	response, err := sendRequest(c, payload, LoadMasterResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (c *Client) ShowACMEAccount(acme_type ACMEProvider) (*ACMEAccountResponse, error) {
+	slog.Debug("Show ACME account", "acme_type", acme_type)
+	payload := struct {
+		*LoadMasterRequest
+		AcmeType ACMEProvider `json:"acmetype"`
+	}{
+		LoadMasterRequest: &LoadMasterRequest{
+			Command: "getacmeaccountinfo",
+		},
+		AcmeType: acme_type,
+	}
+
+	response, err := sendRequest(c, payload, ACMEAccountResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (c *Client) ShowACMERenewPeriod(acme_type ACMEProvider) (*ACMERenewPeriodResponse, error) {
+	slog.Debug("Show ACME renew period", "acme_type", acme_type)
+	payload := struct {
+		*LoadMasterRequest
+		AcmeType ACMEProvider `json:"acmetype"`
+	}{
+		LoadMasterRequest: &LoadMasterRequest{
+			Command: "getacmerenewperiod",
+		},
+		AcmeType: acme_type,
+	}
+
+	response, err := sendRequest(c, payload, ACMERenewPeriodResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// SetACMERenewPeriod sets the number of days before expiry at which certificates are renewed.
+func (c *Client) SetACMERenewPeriod(days int32, acme_type ACMEProvider) (*LoadMasterResponse, error) {
+	slog.Debug("Set ACME renew period", "days", days, "acme_type", acme_type)
+	if days < 1 || days > 60 {
+		return nil, fmt.Errorf("renew period %d is out of range 1-60", days)
+	}
+
+	payload := struct {
+		*LoadMasterRequest
+		RenewPeriod int32        `json:"renewperiod"`
+		AcmeType    ACMEProvider `json:"acmetype"`
+	}{
+		LoadMasterRequest: &LoadMasterRequest{
+			Command: "setacmerenewperiod",
+		},
+		RenewPeriod: days,
+		AcmeType:    acme_type,
+	}
+
+	response, err := sendRequest(c, payload, LoadMasterResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (c *Client) ShowACMEDirectoryURL(acme_type ACMEProvider) (*ACMEDirectoryURLResponse, error) {
+	slog.Debug("Show ACME directory URL", "acme_type", acme_type)
+	payload := struct {
+		*LoadMasterRequest
+		AcmeType ACMEProvider `json:"acmetype"`
+	}{
+		LoadMasterRequest: &LoadMasterRequest{
+			Command: "getacmedirectoryurl",
+		},
+		AcmeType: acme_type,
+	}
+
+	response, err := sendRequest(c, payload, ACMEDirectoryURLResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (c *Client) SetACMEDirectoryURL(url string, acme_type ACMEProvider) (*LoadMasterResponse, error) {
+	slog.Debug("Set ACME directory URL", "url", url, "acme_type", acme_type)
+	payload := struct {
+		*LoadMasterRequest
+		DirectoryURL string       `json:"directoryurl"`
+		AcmeType     ACMEProvider `json:"acmetype"`
+	}{
+		LoadMasterRequest: &LoadMasterRequest{
+			Command: "setacmedirectoryurl",
+		},
+		DirectoryURL: url,
+		AcmeType:     acme_type,
+	}
+
+	response, err := sendRequest(c, payload, LoadMasterResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// ListExpiringACMECertificate returns the ACME certificates which expire within the given duration.
+// Certificates without a valid expiry, such as pending orders, are skipped.
+func (c *Client) ListExpiringACMECertificate(within time.Duration, acme_type ACMEProvider) ([]ACMECertificate, error) {
+	slog.Debug("List expiring ACME Certificates", "within", within, "acme_type", acme_type)
+
+	response, err := c.ListACMECertificate(acme_type)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(within)
+	var expiring []ACMECertificate
+	for _, certificate := range response.Cert {
+		expiry, err := certificate.ExpiresAt()
+		if err != nil {
+			slog.Debug("Skipping ACME Certificate without valid expiry", "name", certificate.Name, "error", err)
+			continue
+		}
+		if expiry.Before(deadline) {
+			expiring = append(expiring, certificate)
+		}
+	}
+
+	return expiring, nil
+}
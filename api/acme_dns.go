@@ -0,0 +1,114 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DNSProvider is the configuration of a DNS API used for DNS-01 challenges.
+type DNSProvider interface {
+	// DnsApi returns the name of the DNS API on the LoadMaster.
+	DnsApi() string
+	// DnsApiParams validates the configuration and serialises it into the dnsapiparams format,
+	// KEY=value pairs separated by semicolons.
+	DnsApiParams() (string, error)
+}
+
+// CloudflareDNSProvider authenticates with an API token or, for legacy accounts, with the global API key and email.
+type CloudflareDNSProvider struct {
+	Token     string
+	AccountID string
+	ZoneID    string
+	Key       string
+	Email     string
+}
+
+type Route53DNSProvider struct {
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+type AzureDNSProvider struct {
+	SubscriptionID string
+	TenantID       string
+	AppID          string
+	ClientSecret   string
+}
+
+type GoDaddyDNSProvider struct {
+	Key    string
+	Secret string
+}
+
+type dnsApiParam struct {
+	key      string
+	value    string
+	required bool
+}
+
+func formatDnsApiParams(dns_api string, params []dnsApiParam) (string, error) {
+	var pairs []string
+	for _, param := range params {
+		if param.value == "" {
+			if param.required {
+				return "", fmt.Errorf("%s requires %s", dns_api, param.key)
+			}
+			continue
+		}
+		if strings.Contains(param.value, ";") {
+			return "", fmt.Errorf("value of %s for %s must not contain ';'", param.key, dns_api)
+		}
+		pairs = append(pairs, param.key+"="+param.value)
+	}
+
+	return strings.Join(pairs, ";"), nil
+}
+
+func (CloudflareDNSProvider) DnsApi() string { return "dns_cf" }
+func (Route53DNSProvider) DnsApi() string    { return "dns_aws" }
+func (AzureDNSProvider) DnsApi() string      { return "dns_azure" }
+func (GoDaddyDNSProvider) DnsApi() string    { return "dns_gd" }
+
+func (p CloudflareDNSProvider) DnsApiParams() (string, error) {
+	if p.Token == "" {
+		if p.Key == "" || p.Email == "" {
+			return "", fmt.Errorf("%s requires CF_Token or CF_Key and CF_Email", p.DnsApi())
+		}
+		return formatDnsApiParams(p.DnsApi(), []dnsApiParam{{"CF_Key", p.Key, true}, {"CF_Email", p.Email, true}})
+	}
+	if p.Key != "" || p.Email != "" {
+		return "", fmt.Errorf("%s accepts either CF_Token or CF_Key and CF_Email", p.DnsApi())
+	}
+
+	return formatDnsApiParams(p.DnsApi(), []dnsApiParam{{"CF_Token", p.Token, true}, {"CF_Account_ID", p.AccountID, false}, {"CF_Zone_ID", p.ZoneID, false}})
+}
+
+func (p Route53DNSProvider) DnsApiParams() (string, error) {
+	return formatDnsApiParams(p.DnsApi(), []dnsApiParam{{"AWS_ACCESS_KEY_ID", p.AccessKeyID, true}, {"AWS_SECRET_ACCESS_KEY", p.SecretAccessKey, true}})
+}
+
+func (p AzureDNSProvider) DnsApiParams() (string, error) {
+	return formatDnsApiParams(p.DnsApi(), []dnsApiParam{
+		{"AZUREDNS_SUBSCRIPTIONID", p.SubscriptionID, true},
+		{"AZUREDNS_TENANTID", p.TenantID, true},
+		{"AZUREDNS_APPID", p.AppID, true},
+		{"AZUREDNS_CLIENTSECRET", p.ClientSecret, true},
+	})
+}
+
+func (p GoDaddyDNSProvider) DnsApiParams() (string, error) {
+	return formatDnsApiParams(p.DnsApi(), []dnsApiParam{{"GD_Key", p.Key, true}, {"GD_Secret", p.Secret, true}})
+}
+
+// SetDNSProvider validates the provider and sets DnsApi and DnsApiParams.
+func (p *RequestACMECertificateParameters) SetDNSProvider(provider DNSProvider) error {
+	params, err := provider.DnsApiParams()
+	if err != nil {
+		return err
+	}
+
+	p.DnsApi = provider.DnsApi()
+	p.DnsApiParams = params
+
+	return nil
+}
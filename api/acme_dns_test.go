@@ -0,0 +1,46 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDNSProvider(t *testing.T) {
+	testCases := []struct {
+		name     string
+		provider DNSProvider
+		dns_api  string
+		want     string
+		wantErr  bool
+	}{
+		{"cloudflare token", CloudflareDNSProvider{Token: "token", ZoneID: "zone"}, "dns_cf", "CF_Token=token;CF_Zone_ID=zone", false},
+		{"cloudflare key", CloudflareDNSProvider{Key: "key", Email: "admin@example.com"}, "dns_cf", "CF_Key=key;CF_Email=admin@example.com", false},
+		{"cloudflare key without email", CloudflareDNSProvider{Key: "key"}, "dns_cf", "", true},
+		{"cloudflare token and key", CloudflareDNSProvider{Token: "token", Key: "key"}, "dns_cf", "", true},
+		{"route53", Route53DNSProvider{AccessKeyID: "id", SecretAccessKey: "secret"}, "dns_aws", "AWS_ACCESS_KEY_ID=id;AWS_SECRET_ACCESS_KEY=secret", false},
+		{"route53 missing secret", Route53DNSProvider{AccessKeyID: "id"}, "dns_aws", "", true},
+		{"azure", AzureDNSProvider{SubscriptionID: "s", TenantID: "t", AppID: "a", ClientSecret: "c"}, "dns_azure", "AZUREDNS_SUBSCRIPTIONID=s;AZUREDNS_TENANTID=t;AZUREDNS_APPID=a;AZUREDNS_CLIENTSECRET=c", false},
+		{"azure missing tenant", AzureDNSProvider{SubscriptionID: "s", AppID: "a", ClientSecret: "c"}, "dns_azure", "", true},
+		{"godaddy", GoDaddyDNSProvider{Key: "key", Secret: "secret"}, "dns_gd", "GD_Key=key;GD_Secret=secret", false},
+		{"base64 padded secret", GoDaddyDNSProvider{Key: "key", Secret: "c2VjcmV0=="}, "dns_gd", "GD_Key=key;GD_Secret=c2VjcmV0==", false},
+		{"godaddy separator in value", GoDaddyDNSProvider{Key: "key", Secret: "a;b"}, "dns_gd", "", true},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.dns_api, tt.provider.DnsApi())
+
+			params := &RequestACMECertificateParameters{}
+			err := params.SetDNSProvider(tt.provider)
+			if tt.wantErr {
+				assert.Error(t, err)
+				assert.Empty(t, params.DnsApi)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.dns_api, params.DnsApi)
+			assert.Equal(t, tt.want, params.DnsApiParams)
+		})
+	}
+}
@@ -6,6 +6,10 @@ import (
 	"net/http/httptest"
 	"reflect"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestClient_RegisterLetsEncryptAccount(t *testing.T) {
@@ -170,9 +174,9 @@ func TestClient_RequestACMECertificate(t *testing.T) {
 		want      *LoadMasterResponse
 		wantErr   bool
 	}{
-		{"success response", []any{"name", "common", "1", "1", nil}, `{"code": 200, "message": "OK", "status": "success"}`, &LoadMasterResponse{Code: 200, Message: "OK", Status: "success"}, false},
-		{"success response with params", []any{"name", "common", "1", "1", &RequestACMECertificateParameters{KeySize: 2048}}, `{"code": 200, "message": "OK", "status": "success"}`, &LoadMasterResponse{Code: 200, Message: "OK", Status: "success"}, false},
-		{"fail response", []any{"name", "common", "1", "1", nil}, `fail`, nil, true},
+		{"success response", []any{"name", "common", "1", ACMEProviderLetsEncrypt, nil}, `{"code": 200, "message": "OK", "status": "success"}`, &LoadMasterResponse{Code: 200, Message: "OK", Status: "success"}, false},
+		{"success response with params", []any{"name", "common", "1", ACMEProviderLetsEncrypt, &RequestACMECertificateParameters{KeySize: 2048}}, `{"code": 200, "message": "OK", "status": "success"}`, &LoadMasterResponse{Code: 200, Message: "OK", Status: "success"}, false},
+		{"fail response", []any{"name", "common", "1", ACMEProviderLetsEncrypt, nil}, `fail`, nil, true},
 	}
 	for _, tt := range testCases {
 		t.Run(tt.name, func(t *testing.T) {
@@ -189,7 +193,7 @@ func TestClient_RequestACMECertificate(t *testing.T) {
 			name, _ := tt.arguments[0].(string)
 			common_name, _ := tt.arguments[1].(string)
 			vs_identifier, _ := tt.arguments[2].(string)
-			acme_type, _ := tt.arguments[3].(string)
+			acme_type, _ := tt.arguments[3].(ACMEProvider)
 			params, _ := tt.arguments[4].(*RequestACMECertificateParameters)
 
 			rs, err := client.RequestACMECertificate(name, common_name, vs_identifier, acme_type, params)
@@ -213,8 +217,8 @@ func TestClient_DeleteACMECertificate(t *testing.T) {
 		want      *LoadMasterResponse
 		wantErr   bool
 	}{
-		{"success response", []any{"name", "1"}, `{"code": 200, "message": "OK", "status": "success"}`, &LoadMasterResponse{Code: 200, Message: "OK", Status: "success"}, false},
-		{"fail response", []any{"name", "1"}, `fail`, nil, true},
+		{"success response", []any{"name", ACMEProviderLetsEncrypt}, `{"code": 200, "message": "OK", "status": "success"}`, &LoadMasterResponse{Code: 200, Message: "OK", Status: "success"}, false},
+		{"fail response", []any{"name", ACMEProviderLetsEncrypt}, `fail`, nil, true},
 	}
 	for _, tt := range testCases {
 		t.Run(tt.name, func(t *testing.T) {
@@ -229,7 +233,7 @@ func TestClient_DeleteACMECertificate(t *testing.T) {
 			client := createClientForUnit(server, "baz")
 
 			name, _ := tt.arguments[0].(string)
-			acme_type, _ := tt.arguments[1].(string)
+			acme_type, _ := tt.arguments[1].(ACMEProvider)
 
 			rs, err := client.DeleteACMECertificate(name, acme_type)
 
@@ -243,3 +247,57 @@ func TestClient_DeleteACMECertificate(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_ACMECertificateLifecycle(t *testing.T) {
+	expiry := time.Now().Add(10 * 24 * time.Hour).UTC().Format(time.RFC3339)
+	server := createCommandServer(map[string][]string{
+		"listacmecert":        {`{"code": 200, "status": "ok", "cert": [ { "name": "soon", "cn": "soon.example.com", "expiry": "` + expiry + `" }, { "name": "later", "expiry": "2099-01-01 00:00:00" }, { "name": "pending", "expiry": "" } ]}`},
+		"getacmecert":         {`{"code": 200, "status": "ok", "cert": { "name": "soon", "status": "valid", "expiry": "` + expiry + `" }}`},
+		"renewacmecert":       {`{"code": 200, "status": "ok"}`},
+		"getacmeaccountinfo":  {`{"code": 200, "status": "ok", "AccountID": "42", "Email": "admin@example.com"}`},
+		"getacmerenewperiod":  {`{"code": 200, "status": "ok", "renewperiod": 30}`},
+		"setacmerenewperiod":  {`{"code": 200, "status": "ok"}`},
+		"getacmedirectoryurl": {`{"code": 200, "status": "ok", "directoryurl": "https://acme.example.com/directory"}`},
+		"setacmedirectoryurl": {`{"code": 200, "status": "ok"}`},
+	})
+	defer server.Close()
+	client := createClientForUnit(server.Server, "baz")
+
+	expiring, err := client.ListExpiringACMECertificate(30*24*time.Hour, ACMEProviderLetsEncrypt)
+	require.NoError(t, err)
+	require.Len(t, expiring, 1)
+	assert.Equal(t, "soon.example.com", expiring[0].CommonName)
+	assert.Equal(t, "1", server.Requests[0]["acmetype"])
+
+	certificate, err := client.ShowACMECertificate("soon", ACMEProviderDigicert)
+	require.NoError(t, err)
+	assert.Equal(t, "valid", certificate.Cert.Status)
+	assert.Equal(t, "2", server.Requests[1]["acmetype"])
+
+	_, err = client.RenewACMECertificate("soon", ACMEProviderLetsEncrypt)
+	require.NoError(t, err)
+
+	account, err := client.ShowACMEAccount(ACMEProviderLetsEncrypt)
+	require.NoError(t, err)
+	assert.Equal(t, "42", account.AccountID)
+
+	period, err := client.ShowACMERenewPeriod(ACMEProviderLetsEncrypt)
+	require.NoError(t, err)
+	assert.Equal(t, int32(30), period.RenewPeriod)
+
+	_, err = client.SetACMERenewPeriod(0, ACMEProviderLetsEncrypt)
+	assert.Error(t, err)
+	_, err = client.SetACMERenewPeriod(20, ACMEProviderLetsEncrypt)
+	require.NoError(t, err)
+
+	directory, err := client.ShowACMEDirectoryURL(ACMEProviderLetsEncrypt)
+	require.NoError(t, err)
+	assert.Equal(t, "https://acme.example.com/directory", directory.DirectoryURL)
+
+	_, err = client.SetACMEDirectoryURL("https://acme-staging.example.com/directory", ACMEProviderLetsEncrypt)
+	require.NoError(t, err)
+	assert.Equal(t, "https://acme-staging.example.com/directory", server.Requests[len(server.Requests)-1]["directoryurl"])
+
+	_, err = ACMECertificate{Name: "broken", Expiry: "soon"}.ExpiresAt()
+	assert.Error(t, err)
+}
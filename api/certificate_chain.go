@@ -0,0 +1,171 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+type CompleteChainResponse struct {
+	*LoadMasterResponse
+	// Chain holds the names of the intermediate certificates from the issuer of the leaf upwards.
+	Chain              []string
+	Uploaded           []string
+	VirtualServices    []int32
+	SubVirtualServices []int32
+}
+
+// selfSigned checks the signature directly, as CheckSignatureFrom rejects self-signed leafs without the CA flag.
+func (c ParsedCertificate) selfSigned() bool {
+	return c.Issuer == c.Subject && c.X509 != nil && c.X509.CheckSignature(c.X509.SignatureAlgorithm, c.X509.RawTBSCertificate, c.X509.Signature) == nil
+}
+
+func (c ParsedCertificate) issuedBy(issuer ParsedCertificate) bool {
+	return c.Issuer == issuer.Subject && c.X509 != nil && issuer.X509 != nil && c.X509.CheckSignatureFrom(issuer.X509) == nil
+}
+
+// CompleteCertificateChain builds the chain of the certificate from the installed intermediates and the
+// certificates of the PEM bundle. Intermediates missing on the LoadMaster are uploaded as "<name>_intermediate<n>"
+// with the first free n, root certificates are never uploaded. Afterwards IntermediateCerts of every virtual service
+// and sub virtual service using the certificate is set to the chain. If any step fails, all changes are reverted.
+func (c *Client) CompleteCertificateChain(name string, bundle []byte) (*CompleteChainResponse, error) {
+	slog.Debug("Completing certificate chain", "name", name)
+	var undo rollback
+
+	leaf, err := c.ShowParsedCertificate(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []ParsedCertificate
+	for rest := bundle; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		parsed, err := ParseCertificateData(string(pem.EncodeToMemory(block)))
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, parsed[0])
+	}
+
+	installed, err := c.listParsedCertificateType(true)
+	if err != nil {
+		return nil, err
+	}
+
+	var taken []string
+	for _, intermediate := range installed {
+		taken = append(taken, intermediate.Name)
+	}
+
+	result := &CompleteChainResponse{}
+	current := leaf.Certificates[0]
+	// The length limit guards against cross-signed certificates issuing each other.
+	for !current.selfSigned() && len(result.Chain) < 10 {
+		var issuer *ParsedCertificate
+		for i := range installed {
+			if current.issuedBy(installed[i]) {
+				issuer = &installed[i]
+				break
+			}
+		}
+
+		if issuer == nil {
+			for i := range candidates {
+				if !current.issuedBy(candidates[i]) || candidates[i].selfSigned() {
+					continue
+				}
+
+				candidate := candidates[i]
+				candidate.Name = nextIntermediateName(name, taken)
+				taken = append(taken, candidate.Name)
+				data := base64.StdEncoding.EncodeToString(encodeCertificatePEM(candidate.X509))
+				_, err := c.AddIntermediateCertificate(candidate.Name, data)
+				if err != nil {
+					return nil, undo.run(err)
+				}
+				undo.push(func() error {
+					_, err := c.DeleteIntermediateCertificate(candidate.Name)
+					return err
+				})
+				result.Uploaded = append(result.Uploaded, candidate.Name)
+				issuer = &candidate
+				break
+			}
+		}
+
+		// The issuer of the last intermediate is expected to be a root known to the clients.
+		if issuer == nil {
+			if len(result.Chain) == 0 {
+				return nil, undo.run(fmt.Errorf("issuer %s of certificate %s is neither installed nor part of the bundle", current.Issuer, name))
+			}
+			break
+		}
+
+		result.Chain = append(result.Chain, issuer.Name)
+		current = *issuer
+	}
+
+	services, err := c.ListVirtualService()
+	if err != nil {
+		return nil, undo.run(err)
+	}
+	result.LoadMasterResponse = services.LoadMasterResponse
+
+	chain := strings.Join(result.Chain, " ")
+	// A self-signed certificate has no chain to assign.
+	if chain == "" {
+		return result, nil
+	}
+	for _, user := range certificateUsers(services.VS, name) {
+		previous_value := user.IntermediateCerts
+		if previous_value == chain {
+			continue
+		}
+
+		identifier := strconv.Itoa(int(user.Index))
+		err := c.setIntermediateCerts(identifier, chain)
+		if err != nil {
+			return nil, undo.run(err)
+		}
+		undo.push(func() error {
+			return c.setIntermediateCerts(identifier, previous_value)
+		})
+		if user.SubVS {
+			result.SubVirtualServices = append(result.SubVirtualServices, user.Index)
+		} else {
+			result.VirtualServices = append(result.VirtualServices, user.Index)
+		}
+	}
+
+	return result, nil
+}
+
+// setIntermediateCerts sets IntermediateCerts of a virtual service or sub virtual service. Unlike modifySSLProperties
+// it also sends an empty value, which clears the assigned intermediates.
+func (c *Client) setIntermediateCerts(identifier string, value string) error {
+	payload := struct {
+		*LoadMasterRequest
+		VS                string `json:"vs"`
+		IntermediateCerts string `json:"IntermediateCerts"`
+	}{
+		LoadMasterRequest: &LoadMasterRequest{
+			Command: "modvs",
+		},
+		VS:                identifier,
+		IntermediateCerts: value,
+	}
+
+	_, err := sendRequest(c, payload, LoadMasterResponse{})
+
+	return err
+}
@@ -0,0 +1,137 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_CompleteCertificateChain(t *testing.T) {
+	not_after := time.Now().Add(90 * 24 * time.Hour)
+	root := createTestCertificate(t, "Root CA", nil, not_after, nil)
+	upper := createTestCertificate(t, "Upper CA", nil, not_after, root)
+	lower := createTestCertificate(t, "Lower CA", nil, not_after, upper)
+	leaf := createTestCertificate(t, "example.com", []string{"example.com"}, not_after, lower)
+
+	show := func(certificate *testCertificate) string {
+		encoded, _ := json.Marshal(map[string]any{"code": 200, "status": "ok", "certificate": certificate.PEM})
+		return string(encoded)
+	}
+	listvs := `{"code": 200, "status": "ok", "VS": [ { "Index": 1, "CertFile": "example" }, { "Index": 2, "CertFile": "other" } ]}`
+
+	t.Run("success", func(t *testing.T) {
+		server := createCommandServer(map[string][]string{
+			"readcert":         {show(leaf)},
+			"listintermediate": {`{"code": 200, "status": "ok", "cert": [ { "name": "upper" } ]}`},
+			"readintermediate": {show(upper)},
+			"addintermediate":  {`{"code": 200, "status": "ok"}`},
+			"listvs":           {listvs},
+			"modvs":            {`{"code": 200, "status": "ok"}`},
+		})
+		defer server.Close()
+		client := createClientForUnit(server.Server, "baz")
+
+		response, err := client.CompleteCertificateChain("example", []byte(root.PEM+lower.PEM))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"example_intermediate1", "upper"}, response.Chain)
+		assert.Equal(t, []string{"example_intermediate1"}, response.Uploaded)
+		assert.Equal(t, []int32{1}, response.VirtualServices)
+		assert.Equal(t, []string{"readcert", "listintermediate", "readintermediate", "addintermediate", "listvs", "modvs"}, server.Commands)
+
+		uploaded, err := base64.StdEncoding.DecodeString(server.Requests[3]["data"].(string))
+		require.NoError(t, err)
+		assert.Equal(t, lower.PEM, string(uploaded))
+		assert.Equal(t, "example_intermediate1 upper", server.Requests[5]["IntermediateCerts"])
+	})
+
+	t.Run("name taken", func(t *testing.T) {
+		server := createCommandServer(map[string][]string{
+			"readcert":         {show(leaf)},
+			"listintermediate": {`{"code": 200, "status": "ok", "cert": [ { "name": "example_intermediate1" } ]}`},
+			"readintermediate": {show(upper)},
+			"addintermediate":  {`{"code": 200, "status": "ok"}`},
+			"listvs":           {listvs},
+			"modvs":            {`{"code": 200, "status": "ok"}`},
+		})
+		defer server.Close()
+		client := createClientForUnit(server.Server, "baz")
+
+		response, err := client.CompleteCertificateChain("example", []byte(lower.PEM))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"example_intermediate2", "example_intermediate1"}, response.Chain)
+		assert.Equal(t, "example_intermediate2", server.Requests[3]["cert"])
+	})
+
+	t.Run("missing issuer", func(t *testing.T) {
+		server := createCommandServer(map[string][]string{
+			"readcert":         {show(leaf)},
+			"listintermediate": {`{"code": 200, "status": "ok", "cert": []}`},
+		})
+		defer server.Close()
+		client := createClientForUnit(server.Server, "baz")
+
+		_, err := client.CompleteCertificateChain("example", []byte(root.PEM))
+		assert.ErrorContains(t, err, "CN=Lower CA")
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		server := createCommandServer(map[string][]string{
+			"readcert":         {show(leaf)},
+			"listintermediate": {`{"code": 200, "status": "ok", "cert": []}`},
+			"addintermediate":  {`{"code": 200, "status": "ok"}`},
+			"listvs":           {listvs},
+			"modvs":            {`!{"code": 400, "status": "fail", "message": "failed"}`},
+			"delintermediate":  {`{"code": 200, "status": "ok"}`},
+		})
+		defer server.Close()
+		client := createClientForUnit(server.Server, "baz")
+
+		_, err := client.CompleteCertificateChain("example", []byte(lower.PEM+upper.PEM+root.PEM))
+		assert.Error(t, err)
+		assert.Equal(t, []string{"readcert", "listintermediate", "addintermediate", "addintermediate", "listvs", "modvs", "delintermediate", "delintermediate"}, server.Commands)
+		assert.Equal(t, "example_intermediate2", server.Requests[6]["cert"])
+	})
+
+	t.Run("rollback clears intermediates", func(t *testing.T) {
+		server := createCommandServer(map[string][]string{
+			"readcert":         {show(leaf)},
+			"listintermediate": {`{"code": 200, "status": "ok", "cert": [ { "name": "lower" } ]}`},
+			"readintermediate": {show(lower)},
+			"addintermediate":  {`{"code": 200, "status": "ok"}`},
+			"delintermediate":  {`{"code": 200, "status": "ok"}`},
+			"listvs":           {`{"code": 200, "status": "ok", "VS": [ { "Index": 1, "CertFile": "example" }, { "Index": 3, "CertFile": "example", "IntermediateCerts": "old" } ]}`},
+			"modvs":            {`{"code": 200, "status": "ok"}`, `!{"code": 400, "status": "fail", "message": "failed"}`, `{"code": 200, "status": "ok"}`},
+		})
+		defer server.Close()
+		client := createClientForUnit(server.Server, "baz")
+
+		_, err := client.CompleteCertificateChain("example", []byte(upper.PEM))
+		assert.Error(t, err)
+		assert.Equal(t, []string{"readcert", "listintermediate", "readintermediate", "addintermediate", "listvs", "modvs", "modvs", "modvs", "delintermediate"}, server.Commands)
+		restore := server.Requests[7]
+		assert.Equal(t, "1", restore["vs"])
+		assert.Contains(t, restore, "IntermediateCerts")
+		assert.Equal(t, "", restore["IntermediateCerts"])
+	})
+
+	t.Run("self-signed", func(t *testing.T) {
+		self_signed := createTestCertificate(t, "self.example.com", []string{"self.example.com"}, not_after, nil)
+		server := createCommandServer(map[string][]string{
+			"readcert":         {show(self_signed)},
+			"listintermediate": {`{"code": 200, "status": "ok", "cert": []}`},
+			"listvs":           {listvs},
+		})
+		defer server.Close()
+		client := createClientForUnit(server.Server, "baz")
+
+		response, err := client.CompleteCertificateChain("example", nil)
+		require.NoError(t, err)
+		assert.Empty(t, response.Chain)
+		assert.Empty(t, response.VirtualServices)
+		assert.Equal(t, []string{"readcert", "listintermediate", "listvs"}, server.Commands)
+	})
+}
@@ -0,0 +1,253 @@
+package api
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+type ParsedCertificate struct {
+	Name           string
+	Intermediate   bool
+	Subject        string
+	CommonName     string
+	DNSNames       []string
+	IPAddresses    []string
+	EmailAddresses []string
+	Issuer         string
+	SerialNumber   string
+	NotBefore      time.Time
+	NotAfter       time.Time
+	IsCA           bool
+	// KeyType is RSA, ECDSA or Ed25519. KeySize is given in bits.
+	KeyType           string
+	KeySize           int
+	SHA1Fingerprint   string
+	SHA256Fingerprint string
+	X509              *x509.Certificate `json:"-"`
+}
+
+type ParsedCertResponse struct {
+	*LoadMasterResponse
+	// Certificates holds the certificate followed by any chain certificates stored with it.
+	Certificates []ParsedCertificate
+}
+
+// ParseCertificateData decodes certificate data as returned by the LoadMaster.
+// The data may be PEM, base64 encoded PEM or DER, or base64 encoded DER.
+func ParseCertificateData(data string) ([]ParsedCertificate, error) {
+	raw := []byte(strings.TrimSpace(data))
+	if !strings.Contains(string(raw), "-----BEGIN") {
+		decoded, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(string(raw)), ""))
+		if err == nil {
+			raw = decoded
+		}
+	}
+
+	var ders [][]byte
+	if strings.Contains(string(raw), "-----BEGIN") {
+		for {
+			var block *pem.Block
+			block, raw = pem.Decode(raw)
+			if block == nil {
+				break
+			}
+			if block.Type == "CERTIFICATE" {
+				ders = append(ders, block.Bytes)
+			}
+		}
+	} else {
+		ders = append(ders, raw)
+	}
+
+	var certificates []ParsedCertificate
+	for _, der := range ders {
+		certificate, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse certificate: %w", err)
+		}
+		certificates = append(certificates, newParsedCertificate(certificate))
+	}
+	if len(certificates) == 0 {
+		return nil, fmt.Errorf("no certificate found in data")
+	}
+
+	return certificates, nil
+}
+
+func newParsedCertificate(certificate *x509.Certificate) ParsedCertificate {
+	parsed := ParsedCertificate{
+		Subject:        certificate.Subject.String(),
+		CommonName:     certificate.Subject.CommonName,
+		DNSNames:       certificate.DNSNames,
+		EmailAddresses: certificate.EmailAddresses,
+		Issuer:         certificate.Issuer.String(),
+		SerialNumber:   certificate.SerialNumber.String(),
+		NotBefore:      certificate.NotBefore,
+		NotAfter:       certificate.NotAfter,
+		IsCA:           certificate.IsCA,
+		X509:           certificate,
+	}
+	sha1_sum := sha1.Sum(certificate.Raw)
+	parsed.SHA1Fingerprint = fingerprint(sha1_sum[:])
+	sha256_sum := sha256.Sum256(certificate.Raw)
+	parsed.SHA256Fingerprint = fingerprint(sha256_sum[:])
+	for _, ip := range certificate.IPAddresses {
+		parsed.IPAddresses = append(parsed.IPAddresses, ip.String())
+	}
+
+	switch key := certificate.PublicKey.(type) {
+	case *rsa.PublicKey:
+		parsed.KeyType = "RSA"
+		parsed.KeySize = key.N.BitLen()
+	case *ecdsa.PublicKey:
+		parsed.KeyType = "ECDSA"
+		parsed.KeySize = key.Curve.Params().BitSize
+	case ed25519.PublicKey:
+		parsed.KeyType = "Ed25519"
+		parsed.KeySize = 256
+	}
+
+	return parsed
+}
+
+func fingerprint(sum []byte) string {
+	var parts []string
+	for _, b := range sum {
+		parts = append(parts, fmt.Sprintf("%02X", b))
+	}
+
+	return strings.Join(parts, ":")
+}
+
+// Expired reports whether the certificate is no longer valid at the given time.
+func (c ParsedCertificate) Expired(now time.Time) bool {
+	return now.After(c.NotAfter)
+}
+
+// ExpiresWithin reports whether the certificate expires before now plus the given duration.
+func (c ParsedCertificate) ExpiresWithin(now time.Time, within time.Duration) bool {
+	return c.NotAfter.Before(now.Add(within))
+}
+
+// Hostnames returns the DNS names of the certificate, or the common name if there are none.
+func (c ParsedCertificate) Hostnames() []string {
+	if len(c.DNSNames) > 0 {
+		return c.DNSNames
+	}
+	if c.CommonName != "" {
+		return []string{c.CommonName}
+	}
+
+	return nil
+}
+
+func (r *ShowCertResponse) Certificates() ([]ParsedCertificate, error) {
+	return ParseCertificateData(r.Data)
+}
+
+func (c *Client) ShowParsedCertificate(name string) (*ParsedCertResponse, error) {
+	slog.Debug("Show parsed certificate", "name", name)
+
+	response, err := c.ShowCertificate(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return parsedCertResponse(response, name, false)
+}
+
+func (c *Client) ShowParsedIntermediateCertificate(name string) (*ParsedCertResponse, error) {
+	slog.Debug("Show parsed intermediate certificate", "name", name)
+
+	response, err := c.ShowIntermediateCertificate(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return parsedCertResponse(response, name, true)
+}
+
+func parsedCertResponse(response *ShowCertResponse, name string, intermediate bool) (*ParsedCertResponse, error) {
+	certificates, err := response.Certificates()
+	if err != nil {
+		return nil, fmt.Errorf("certificate %s: %w", name, err)
+	}
+	for i := range certificates {
+		certificates[i].Name = name
+		certificates[i].Intermediate = intermediate
+	}
+
+	return &ParsedCertResponse{LoadMasterResponse: response.LoadMasterResponse, Certificates: certificates}, nil
+}
+
+// listParsedCertificate parses every certificate followed by every intermediate certificate.
+// Only the first certificate of each entry is returned.
+func (c *Client) listParsedCertificate() ([]ParsedCertificate, error) {
+	certificates, err := c.listParsedCertificateType(false)
+	if err != nil {
+		return nil, err
+	}
+	intermediates, err := c.listParsedCertificateType(true)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(certificates, intermediates...), nil
+}
+
+// listParsedCertificateType parses every installed certificate, or every intermediate certificate if intermediate is set.
+func (c *Client) listParsedCertificateType(intermediate bool) ([]ParsedCertificate, error) {
+	list := c.ListCertificate
+	show := c.ShowParsedCertificate
+	if intermediate {
+		list = c.ListIntermediateCertificate
+		show = c.ShowParsedIntermediateCertificate
+	}
+
+	certificates, err := list()
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed []ParsedCertificate
+	for _, info := range certificates.Cert {
+		response, err := show(info.Name)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, response.Certificates[0])
+	}
+
+	return parsed, nil
+}
+
+// ListExpiringCertificate returns all certificates and intermediate certificates which expire within the given duration,
+// including the ones which are already expired.
+func (c *Client) ListExpiringCertificate(within time.Duration) ([]ParsedCertificate, error) {
+	slog.Debug("Listing expiring certificates", "within", within)
+	now := time.Now()
+
+	certificates, err := c.listParsedCertificate()
+	if err != nil {
+		return nil, err
+	}
+
+	var expiring []ParsedCertificate
+	for _, certificate := range certificates {
+		if certificate.ExpiresWithin(now, within) {
+			expiring = append(expiring, certificate)
+		}
+	}
+
+	return expiring, nil
+}
@@ -0,0 +1,138 @@
+package api
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCertificate struct {
+	Certificate *x509.Certificate
+	Key         crypto.Signer
+	PEM         string
+}
+
+// createTestCertificate issues a certificate for the common name and DNS names which is signed by the parent,
+// or self-signed if parent is nil.
+func createTestCertificate(t *testing.T, common_name string, dns_names []string, not_after time.Time, parent *testCertificate) *testCertificate {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: common_name},
+		DNSNames:              dns_names,
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              not_after,
+		BasicConstraintsValid: true,
+		IsCA:                  len(dns_names) == 0,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+	}
+
+	issuer, signer := template, crypto.Signer(key)
+	if parent != nil {
+		issuer, signer = parent.Certificate, parent.Key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, issuer, key.Public(), signer)
+	require.NoError(t, err)
+	certificate, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCertificate{
+		Certificate: certificate,
+		Key:         key,
+		PEM:         string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+	}
+}
+
+func (c *testCertificate) keyPEM(t *testing.T) string {
+	t.Helper()
+
+	der, err := x509.MarshalPKCS8PrivateKey(c.Key)
+	require.NoError(t, err)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+}
+
+func TestParseCertificateData(t *testing.T) {
+	not_after := time.Now().Add(10 * 24 * time.Hour).Truncate(time.Second)
+	ca := createTestCertificate(t, "Test CA", nil, not_after.Add(time.Hour), nil)
+	leaf := createTestCertificate(t, "example.com", []string{"example.com", "www.example.com"}, not_after, ca)
+
+	for name, data := range map[string]string{
+		"pem":        leaf.PEM + ca.PEM,
+		"base64 pem": base64.StdEncoding.EncodeToString([]byte(leaf.PEM + ca.PEM)),
+		"base64 der": base64.StdEncoding.EncodeToString(leaf.Certificate.Raw),
+	} {
+		t.Run(name, func(t *testing.T) {
+			certificates, err := ParseCertificateData(data)
+			require.NoError(t, err)
+
+			certificate := certificates[0]
+			assert.Equal(t, "example.com", certificate.CommonName)
+			assert.Equal(t, []string{"example.com", "www.example.com"}, certificate.Hostnames())
+			assert.Equal(t, "CN=Test CA", certificate.Issuer)
+			assert.Equal(t, "ECDSA", certificate.KeyType)
+			assert.Equal(t, 256, certificate.KeySize)
+			assert.Len(t, certificate.SHA256Fingerprint, 32*3-1)
+			assert.True(t, certificate.NotAfter.Equal(not_after))
+			assert.True(t, certificate.ExpiresWithin(time.Now(), 30*24*time.Hour))
+			assert.False(t, certificate.ExpiresWithin(time.Now(), 24*time.Hour))
+			assert.False(t, certificate.Expired(time.Now()))
+		})
+	}
+
+	_, err := ParseCertificateData("invalid")
+	assert.Error(t, err)
+}
+
+func TestNewParsedCertificateIPAddresses(t *testing.T) {
+	parsed := newParsedCertificate(&x509.Certificate{IPAddresses: []net.IP{net.ParseIP("10.0.0.1")}, SerialNumber: big.NewInt(1), PublicKey: ecdsa.PublicKey{}})
+	assert.Equal(t, []string{"10.0.0.1"}, parsed.IPAddresses)
+}
+
+func TestClient_ListExpiringCertificate(t *testing.T) {
+	soon := createTestCertificate(t, "soon.example.com", []string{"soon.example.com"}, time.Now().Add(5*24*time.Hour), nil)
+	later := createTestCertificate(t, "later.example.com", []string{"later.example.com"}, time.Now().Add(90*24*time.Hour), nil)
+	ca := createTestCertificate(t, "Test CA", nil, time.Now().Add(20*24*time.Hour), nil)
+
+	show := func(data string) string {
+		encoded, _ := json.Marshal(map[string]any{"code": 200, "status": "ok", "certificate": data})
+		return string(encoded)
+	}
+	server := createCommandServer(map[string][]string{
+		"listcert":         {`{"code": 200, "status": "ok", "cert": [ { "name": "soon" }, { "name": "later" } ]}`},
+		"listintermediate": {`{"code": 200, "status": "ok", "cert": [ { "name": "ca" } ]}`},
+		"readcert":         {show(soon.PEM), show(later.PEM)},
+		"readintermediate": {show(ca.PEM)},
+	})
+	defer server.Close()
+	client := createClientForUnit(server.Server, "baz")
+
+	expiring, err := client.ListExpiringCertificate(30 * 24 * time.Hour)
+	require.NoError(t, err)
+	require.Len(t, expiring, 2)
+	assert.Equal(t, "soon", expiring[0].Name)
+	assert.False(t, expiring[0].Intermediate)
+	assert.Equal(t, "ca", expiring[1].Name)
+	assert.True(t, expiring[1].Intermediate)
+}
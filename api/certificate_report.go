@@ -0,0 +1,284 @@
+package api
+
+import (
+	"crypto/x509"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type CertificateStatus string
+
+const (
+	CertificateStatusValid    CertificateStatus = "valid"
+	CertificateStatusExpiring CertificateStatus = "expiring"
+	CertificateStatusExpired  CertificateStatus = "expired"
+)
+
+type CertificateReportEntry struct {
+	Name            string            `json:"name"`
+	Intermediate    bool              `json:"intermediate"`
+	CommonName      string            `json:"common_name"`
+	Hostnames       []string          `json:"hostnames"`
+	Issuer          string            `json:"issuer"`
+	NotAfter        time.Time         `json:"not_after"`
+	DaysRemaining   int               `json:"days_remaining"`
+	Status          CertificateStatus `json:"status"`
+	Unused          bool              `json:"unused"`
+	VirtualServices []int32           `json:"virtual_services"`
+}
+
+// IncompleteChain is a virtual service whose certificate chain cannot be built from the installed intermediates.
+type IncompleteChain struct {
+	VSIndex     int32  `json:"vs_index"`
+	Certificate string `json:"certificate"`
+	// MissingIssuer is the subject of the missing intermediate, or the name of an assigned intermediate which is not installed.
+	MissingIssuer string `json:"missing_issuer"`
+}
+
+type CertificateReport struct {
+	*LoadMasterResponse `json:"-"`
+	GeneratedAt         time.Time                `json:"generated_at"`
+	Certificates        []CertificateReportEntry `json:"certificates"`
+	Expiring            []string                 `json:"expiring"`
+	Expired             []string                 `json:"expired"`
+	Unused              []string                 `json:"unused"`
+	IncompleteChains    []IncompleteChain        `json:"incomplete_chains"`
+}
+
+// splitCertificateNames splits the certificate lists of CertFile and IntermediateCerts.
+func splitCertificateNames(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return r == ' ' || r == ','
+	})
+}
+
+func (vs *VirtualService) certificateNames() []string {
+	if vs.VirtualServiceParameters == nil || vs.VirtualServiceParametersSSLProperties == nil {
+		return nil
+	}
+
+	return splitCertificateNames(vs.CertFile)
+}
+
+func (vs *VirtualService) intermediateCertificateNames() []string {
+	if vs.VirtualServiceParameters == nil || vs.VirtualServiceParametersSSLProperties == nil {
+		return nil
+	}
+
+	return splitCertificateNames(vs.IntermediateCerts)
+}
+
+// BuildCertificateReport maps the certificates to the virtual services using them and classifies them.
+// Certificates expiring before now plus within are reported as expiring. The chain of every certificate of a virtual
+// service is walked through the intermediates the virtual service uses, which are the assigned intermediates or, if
+// none are assigned, all installed intermediates. A chain is incomplete if it ends in a certificate which is neither
+// self-signed nor issued by a system root. Intermediates are used if they are assigned to or part of the chain of
+// a virtual service.
+func BuildCertificateReport(certificates []ParsedCertificate, services []VirtualService, now time.Time, within time.Duration) *CertificateReport {
+	report := &CertificateReport{GeneratedAt: now}
+
+	leafs := map[string]ParsedCertificate{}
+	intermediates := map[string]ParsedCertificate{}
+	for _, certificate := range certificates {
+		if certificate.Intermediate {
+			intermediates[certificate.Name] = certificate
+		} else {
+			leafs[certificate.Name] = certificate
+		}
+	}
+
+	users := map[string][]int32{}
+	intermediate_users := map[string][]int32{}
+	for i := range services {
+		vs := &services[i]
+		for _, name := range vs.certificateNames() {
+			users[name] = append(users[name], vs.Index)
+		}
+
+		assigned := vs.intermediateCertificateNames()
+		var available []ParsedCertificate
+		for _, name := range assigned {
+			intermediate_users[name] = append(intermediate_users[name], vs.Index)
+			intermediate, ok := intermediates[name]
+			if !ok {
+				// A missing assigned intermediate breaks the chain of every certificate of the virtual service.
+				names := vs.certificateNames()
+				if len(names) == 0 {
+					names = []string{""}
+				}
+				for _, certificate := range names {
+					report.IncompleteChains = append(report.IncompleteChains, IncompleteChain{VSIndex: vs.Index, Certificate: certificate, MissingIssuer: name})
+				}
+				continue
+			}
+			available = append(available, intermediate)
+		}
+		if len(assigned) == 0 {
+			for _, certificate := range certificates {
+				if certificate.Intermediate {
+					available = append(available, certificate)
+				}
+			}
+		}
+
+		for _, name := range vs.certificateNames() {
+			leaf, ok := leafs[name]
+			if !ok {
+				continue
+			}
+			chain, missing := certificateChain(leaf, available)
+			for _, intermediate := range chain {
+				if !slices.Contains(intermediate_users[intermediate.Name], vs.Index) {
+					intermediate_users[intermediate.Name] = append(intermediate_users[intermediate.Name], vs.Index)
+				}
+			}
+			if missing != "" {
+				report.IncompleteChains = append(report.IncompleteChains, IncompleteChain{VSIndex: vs.Index, Certificate: name, MissingIssuer: missing})
+			}
+		}
+	}
+
+	for _, certificate := range certificates {
+		entry := CertificateReportEntry{
+			Name:            certificate.Name,
+			Intermediate:    certificate.Intermediate,
+			CommonName:      certificate.CommonName,
+			Hostnames:       certificate.Hostnames(),
+			Issuer:          certificate.Issuer,
+			NotAfter:        certificate.NotAfter,
+			DaysRemaining:   int(certificate.NotAfter.Sub(now).Hours() / 24),
+			Status:          CertificateStatusValid,
+			VirtualServices: users[certificate.Name],
+		}
+		if certificate.Intermediate {
+			entry.VirtualServices = intermediate_users[certificate.Name]
+		}
+		switch {
+		case certificate.Expired(now):
+			entry.Status = CertificateStatusExpired
+			report.Expired = append(report.Expired, certificate.Name)
+		case certificate.ExpiresWithin(now, within):
+			entry.Status = CertificateStatusExpiring
+			report.Expiring = append(report.Expiring, certificate.Name)
+		}
+		if len(entry.VirtualServices) == 0 {
+			entry.Unused = true
+			report.Unused = append(report.Unused, certificate.Name)
+		}
+
+		report.Certificates = append(report.Certificates, entry)
+	}
+
+	return report
+}
+
+// certificateChain walks from the certificate through the available intermediates. It returns the intermediates of
+// the chain and the issuer subject the chain is missing, which is empty if the chain ends in a self-signed certificate
+// or in a certificate issued by a system root.
+func certificateChain(certificate ParsedCertificate, available []ParsedCertificate) ([]ParsedCertificate, string) {
+	var chain []ParsedCertificate
+	for current := certificate; current.Issuer != current.Subject; {
+		index := slices.IndexFunc(available, func(intermediate ParsedCertificate) bool {
+			return intermediate.Subject == current.Issuer && !slices.ContainsFunc(chain, func(c ParsedCertificate) bool { return c.Name == intermediate.Name })
+		})
+		if index < 0 {
+			if current.issuedBySystemRoot() {
+				return chain, ""
+			}
+			return chain, current.Issuer
+		}
+		current = available[index]
+		chain = append(chain, current)
+	}
+
+	return chain, ""
+}
+
+// issuedBySystemRoot reports whether the certificate is directly issued by a root of the system trust store.
+func (c ParsedCertificate) issuedBySystemRoot() bool {
+	if c.X509 == nil {
+		return false
+	}
+
+	_, err := c.X509.Verify(x509.VerifyOptions{CurrentTime: c.NotBefore, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}})
+
+	return err == nil
+}
+
+// ShowCertificateReport parses every certificate and intermediate certificate and runs BuildCertificateReport on them.
+func (c *Client) ShowCertificateReport(within time.Duration) (*CertificateReport, error) {
+	slog.Debug("Showing certificate report", "within", within)
+
+	certificates, err := c.listParsedCertificate()
+	if err != nil {
+		return nil, err
+	}
+
+	services, err := c.ListVirtualService()
+	if err != nil {
+		return nil, err
+	}
+
+	report := BuildCertificateReport(certificates, services.VS, time.Now(), within)
+	report.LoadMasterResponse = services.LoadMasterResponse
+
+	return report, nil
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r *CertificateReport) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(r)
+}
+
+// WriteCSV writes one line per certificate. Hostnames and virtual services are separated by spaces.
+func (r *CertificateReport) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	err := writer.Write([]string{"name", "intermediate", "common_name", "hostnames", "issuer", "not_after", "days_remaining", "status", "unused", "virtual_services", "incomplete_chain"})
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range r.Certificates {
+		var services []string
+		for _, index := range entry.VirtualServices {
+			services = append(services, strconv.Itoa(int(index)))
+		}
+		incomplete := slices.ContainsFunc(r.IncompleteChains, func(chain IncompleteChain) bool {
+			return !entry.Intermediate && chain.Certificate == entry.Name
+		})
+
+		err := writer.Write([]string{
+			entry.Name,
+			strconv.FormatBool(entry.Intermediate),
+			entry.CommonName,
+			strings.Join(entry.Hostnames, " "),
+			entry.Issuer,
+			entry.NotAfter.UTC().Format(time.RFC3339),
+			strconv.Itoa(entry.DaysRemaining),
+			string(entry.Status),
+			strconv.FormatBool(entry.Unused),
+			strings.Join(services, " "),
+			strconv.FormatBool(incomplete),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("failed to write certificate report: %w", err)
+	}
+
+	return nil
+}
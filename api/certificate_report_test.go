@@ -0,0 +1,108 @@
+package api
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildCertificateReport(t *testing.T) {
+	now := time.Now()
+	ca := createTestCertificate(t, "Test CA", nil, now.Add(365*24*time.Hour), nil)
+	other_ca := createTestCertificate(t, "Other CA", nil, now.Add(365*24*time.Hour), nil)
+	expiring := createTestCertificate(t, "expiring.example.com", []string{"expiring.example.com"}, now.Add(10*24*time.Hour), ca)
+	expired := createTestCertificate(t, "expired.example.com", []string{"expired.example.com"}, now.Add(-24*time.Hour), ca)
+	unchained := createTestCertificate(t, "other.example.com", []string{"other.example.com"}, now.Add(90*24*time.Hour), other_ca)
+	missing_root := createTestCertificate(t, "Missing Root", nil, now.Add(365*24*time.Hour), nil)
+	sub_ca := createTestCertificate(t, "Sub CA", nil, now.Add(365*24*time.Hour), missing_root)
+	deep := createTestCertificate(t, "deep.example.com", []string{"deep.example.com"}, now.Add(90*24*time.Hour), sub_ca)
+	unreferenced_ca := createTestCertificate(t, "Unreferenced CA", nil, now.Add(365*24*time.Hour), nil)
+	unreferenced := createTestCertificate(t, "unreferenced.example.com", []string{"unreferenced.example.com"}, now.Add(90*24*time.Hour), unreferenced_ca)
+
+	parse := func(name string, intermediate bool, certificate *testCertificate) ParsedCertificate {
+		parsed := newParsedCertificate(certificate.Certificate)
+		parsed.Name = name
+		parsed.Intermediate = intermediate
+		return parsed
+	}
+	certificates := []ParsedCertificate{
+		parse("expiring", false, expiring),
+		parse("expired", false, expired),
+		parse("other", false, unchained),
+		parse("ca", true, ca),
+		parse("unused_ca", true, createTestCertificate(t, "Unused CA", nil, now.Add(365*24*time.Hour), nil)),
+		parse("deep", false, deep),
+		parse("sub_ca", true, sub_ca),
+		parse("unreferenced", false, unreferenced),
+		parse("unreferenced_ca", true, unreferenced_ca),
+	}
+	ssl := func(cert_file string, intermediates string) *VirtualServiceParameters {
+		return &VirtualServiceParameters{VirtualServiceParametersSSLProperties: &VirtualServiceParametersSSLProperties{CertFile: cert_file, IntermediateCerts: intermediates}}
+	}
+	services := []VirtualService{
+		{Index: 1, VirtualServiceParameters: ssl("expiring other", "")},
+		{Index: 2, VirtualServiceParameters: ssl("expiring", "ca missing_ca")},
+		{Index: 3},
+		{Index: 4, VirtualServiceParameters: ssl("deep", "sub_ca")},
+	}
+
+	report := BuildCertificateReport(certificates, services, now, 30*24*time.Hour)
+
+	assert.Equal(t, []string{"expiring"}, report.Expiring)
+	assert.Equal(t, []string{"expired"}, report.Expired)
+	assert.Equal(t, []string{"expired", "unused_ca", "unreferenced", "unreferenced_ca"}, report.Unused)
+	assert.Equal(t, []int32{1, 2}, report.Certificates[0].VirtualServices)
+	assert.Equal(t, CertificateStatusExpiring, report.Certificates[0].Status)
+	assert.Equal(t, 9, report.Certificates[0].DaysRemaining)
+	assert.Equal(t, []int32{1, 2}, report.Certificates[3].VirtualServices)
+	assert.Equal(t, []IncompleteChain{
+		{VSIndex: 1, Certificate: "other", MissingIssuer: "CN=Other CA"},
+		{VSIndex: 2, Certificate: "expiring", MissingIssuer: "missing_ca"},
+		{VSIndex: 4, Certificate: "deep", MissingIssuer: "CN=Missing Root"},
+	}, report.IncompleteChains)
+
+	var encoded bytes.Buffer
+	require.NoError(t, report.WriteJSON(&encoded))
+	var decoded map[string]any
+	require.NoError(t, json.Unmarshal(encoded.Bytes(), &decoded))
+	assert.Len(t, decoded["certificates"], 9)
+	assert.NotContains(t, decoded, "code")
+
+	var written bytes.Buffer
+	require.NoError(t, report.WriteCSV(&written))
+	records, err := csv.NewReader(&written).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 10)
+	assert.Equal(t, "name", records[0][0])
+	assert.Equal(t, []string{"other", "false", "other.example.com", "other.example.com", "CN=Other CA"}, records[3][:5])
+	assert.Equal(t, "1", records[3][9])
+	assert.Equal(t, "true", records[3][10])
+	assert.Equal(t, "expiring", records[1][0])
+	assert.Equal(t, "true", records[1][10])
+}
+
+func TestClient_ShowCertificateReport(t *testing.T) {
+	leaf := createTestCertificate(t, "example.com", []string{"example.com"}, time.Now().Add(90*24*time.Hour), nil)
+	show, _ := json.Marshal(map[string]any{"code": 200, "status": "ok", "certificate": leaf.PEM})
+
+	server := createCommandServer(map[string][]string{
+		"listcert":         {`{"code": 200, "status": "ok", "cert": [ { "name": "example" } ]}`},
+		"listintermediate": {`{"code": 200, "status": "ok", "cert": []}`},
+		"readcert":         {string(show)},
+		"listvs":           {`{"code": 200, "status": "ok", "VS": [ { "Index": 4, "CertFile": "example" } ]}`},
+	})
+	defer server.Close()
+	client := createClientForUnit(server.Server, "baz")
+
+	report, err := client.ShowCertificateReport(30 * 24 * time.Hour)
+	require.NoError(t, err)
+	require.Len(t, report.Certificates, 1)
+	assert.Equal(t, []int32{4}, report.Certificates[0].VirtualServices)
+	assert.Empty(t, report.Unused)
+	assert.Empty(t, report.IncompleteChains)
+}
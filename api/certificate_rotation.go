@@ -0,0 +1,251 @@
+package api
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+type RotateCertificateResponse struct {
+	*LoadMasterResponse
+	Certificate        ParsedCertificate
+	VirtualServices    []int32
+	SubVirtualServices []int32
+}
+
+// certificateUploadPEM returns the PEM content of upload data, which is either PEM or base64 encoded PEM.
+// It returns nil for other formats such as PKCS#12.
+func certificateUploadPEM(data string) []byte {
+	raw := []byte(data)
+	if !bytes.Contains(raw, []byte("-----BEGIN")) {
+		decoded, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(data), ""))
+		if err != nil || !bytes.Contains(decoded, []byte("-----BEGIN")) {
+			return nil
+		}
+		raw = decoded
+	}
+
+	return raw
+}
+
+func encryptedPEM(content []byte) bool {
+	block, _ := pem.Decode(content)
+	return block != nil && (strings.Contains(block.Type, "ENCRYPTED") || block.Headers["Proc-Type"] != "")
+}
+
+// verifyCertificateKey checks that the PEM content contains a private key matching the first certificate.
+// Encrypted keys cannot be checked locally and are left to the LoadMaster.
+func verifyCertificateKey(content []byte) error {
+	var certificates, keys []byte
+	for rest := content; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		switch {
+		case block.Type == "CERTIFICATE":
+			certificates = append(certificates, pem.EncodeToMemory(block)...)
+		case encryptedPEM(pem.EncodeToMemory(block)):
+			return nil
+		case strings.HasSuffix(block.Type, "PRIVATE KEY"):
+			keys = append(keys, pem.EncodeToMemory(block)...)
+		}
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("certificate data contains no private key")
+	}
+
+	_, err := tls.X509KeyPair(certificates, keys)
+	if err != nil {
+		return fmt.Errorf("private key does not match certificate: %w", err)
+	}
+
+	return nil
+}
+
+// missingHostnames returns the hostnames of previous which the certificate is not valid for. As VerifyHostname
+// ignores the common name, a certificate without DNS names covers the hostname matching its common name.
+func (c ParsedCertificate) missingHostnames(previous ParsedCertificate) []string {
+	var missing []string
+	for _, hostname := range previous.Hostnames() {
+		if c.X509 != nil && c.X509.VerifyHostname(hostname) == nil {
+			continue
+		}
+		if len(c.DNSNames) == 0 && strings.EqualFold(c.CommonName, hostname) {
+			continue
+		}
+		missing = append(missing, hostname)
+	}
+
+	return missing
+}
+
+func replaceCertificateName(value string, old_name string, new_name string) string {
+	names := splitCertificateNames(value)
+	for i, name := range names {
+		if name == old_name {
+			names[i] = new_name
+		}
+	}
+
+	return strings.Join(names, " ")
+}
+
+// verifyCertificateUpload checks that the key of PEM or PKCS#12 upload data matches its certificate and returns the
+// certificate. PKCS#12 data is decoded with the password. Encrypted PEM keys cannot be checked locally and are left
+// to the LoadMaster, which rejects keys it cannot decrypt.
+func verifyCertificateUpload(data string, password *string) (ParsedCertificate, error) {
+	if content := certificateUploadPEM(data); content != nil {
+		if err := verifyCertificateKey(content); err != nil {
+			return ParsedCertificate{}, err
+		}
+		certificates, err := ParseCertificateData(string(content))
+		if err != nil {
+			return ParsedCertificate{}, err
+		}
+		return certificates[0], nil
+	}
+
+	decoded, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(data), ""))
+	if err != nil {
+		return ParsedCertificate{}, fmt.Errorf("certificate data is neither PEM nor base64 encoded: %w", err)
+	}
+	var decode_password string
+	if password != nil {
+		decode_password = *password
+	}
+	key, certificate, _, err := pkcs12.DecodeChain(decoded, decode_password)
+	if err != nil {
+		return ParsedCertificate{}, fmt.Errorf("failed to decode pkcs12 data: %w", err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok || !keyMatchesCertificate(certificate, signer) {
+		return ParsedCertificate{}, fmt.Errorf("private key does not match certificate %s", certificate.Subject)
+	}
+
+	return newParsedCertificate(certificate), nil
+}
+
+// RotateCertificate uploads the new certificate, verifies that its key matches and that it covers every hostname
+// of the old certificate, and repoints every virtual service and sub virtual service using the old certificate.
+// The old certificate is deleted after all services were changed. If any step fails, the services are pointed back
+// to the old certificate and the new certificate is removed again.
+// The data is verified before the upload as in verifyCertificateUpload.
+func (c *Client) RotateCertificate(old_name string, new_name string, password *string, data string) (*RotateCertificateResponse, error) {
+	slog.Debug("Rotating certificate", "old_name", old_name, "new_name", new_name)
+	var undo rollback
+
+	previous, err := c.ShowParsedCertificate(old_name)
+	if err != nil {
+		return nil, err
+	}
+
+	certificate, err := verifyCertificateUpload(data, password)
+	if err != nil {
+		return nil, err
+	}
+	if missing := certificate.missingHostnames(previous.Certificates[0]); len(missing) > 0 {
+		return nil, fmt.Errorf("certificate %s does not cover the hostnames %v of certificate %s", new_name, missing, old_name)
+	}
+
+	_, err = c.AddCertificate(new_name, password, data)
+	if err != nil {
+		return nil, err
+	}
+	undo.push(func() error {
+		_, err := c.DeleteCertificate(new_name)
+		return err
+	})
+
+	uploaded, err := c.ShowParsedCertificate(new_name)
+	if err != nil {
+		return nil, undo.run(err)
+	}
+	if missing := uploaded.Certificates[0].missingHostnames(previous.Certificates[0]); len(missing) > 0 {
+		return nil, undo.run(fmt.Errorf("certificate %s does not cover the hostnames %v of certificate %s", new_name, missing, old_name))
+	}
+
+	services, err := c.ListVirtualService()
+	if err != nil {
+		return nil, undo.run(err)
+	}
+
+	result := &RotateCertificateResponse{Certificate: uploaded.Certificates[0]}
+	for _, user := range certificateUsers(services.VS, old_name) {
+		identifier := strconv.Itoa(int(user.Index))
+		previous_value := user.CertFile
+		err := c.modifySSLProperties(identifier, user.SubVS, VirtualServiceParametersSSLProperties{CertFile: replaceCertificateName(previous_value, old_name, new_name)})
+		if err != nil {
+			return nil, undo.run(err)
+		}
+		undo.push(func() error {
+			return c.modifySSLProperties(identifier, user.SubVS, VirtualServiceParametersSSLProperties{CertFile: previous_value})
+		})
+		if user.SubVS {
+			result.SubVirtualServices = append(result.SubVirtualServices, user.Index)
+		} else {
+			result.VirtualServices = append(result.VirtualServices, user.Index)
+		}
+	}
+
+	response, err := c.DeleteCertificate(old_name)
+	if err != nil {
+		return nil, undo.run(err)
+	}
+	result.LoadMasterResponse = response
+
+	return result, nil
+}
+
+type certificateUser struct {
+	*VirtualService
+	Index int32
+	SubVS bool
+}
+
+// certificateUsers returns the virtual services and sub virtual services whose CertFile contains the certificate.
+// Sub virtual services are taken from the listing itself and from the SubVS entries of their parents.
+func certificateUsers(services []VirtualService, name string) []certificateUser {
+	var users []certificateUser
+	seen := map[int32]bool{}
+	for i := range services {
+		vs := &services[i]
+		if !seen[vs.Index] && slices.Contains(vs.certificateNames(), name) {
+			seen[vs.Index] = true
+			users = append(users, certificateUser{VirtualService: vs, Index: vs.Index, SubVS: vs.MasterVSID != 0})
+		}
+
+		for _, subvs := range vs.subVirtualServices() {
+			if subvs.VirtualService == nil || seen[subvs.VSIndex] || !slices.Contains(subvs.certificateNames(), name) {
+				continue
+			}
+			seen[subvs.VSIndex] = true
+			users = append(users, certificateUser{VirtualService: subvs.VirtualService, Index: subvs.VSIndex, SubVS: true})
+		}
+	}
+
+	return users
+}
+
+func (c *Client) modifySSLProperties(identifier string, subvs bool, properties VirtualServiceParametersSSLProperties) error {
+	parameters := VirtualServiceParameters{VirtualServiceParametersSSLProperties: &properties}
+
+	var err error
+	if subvs {
+		_, err = c.ModifySubVirtualService(identifier, parameters)
+	} else {
+		_, err = c.ModifyVirtualService(identifier, parameters)
+	}
+
+	return err
+}
@@ -0,0 +1,142 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+func TestClient_RotateCertificate(t *testing.T) {
+	not_after := time.Now().Add(90 * 24 * time.Hour)
+	old := createTestCertificate(t, "example.com", []string{"example.com", "www.example.com"}, not_after, nil)
+	renewed := createTestCertificate(t, "example.com", []string{"example.com", "*.example.com"}, not_after, nil)
+	narrow := createTestCertificate(t, "example.com", []string{"example.com"}, not_after, nil)
+
+	show := func(certificate *testCertificate) string {
+		encoded, _ := json.Marshal(map[string]any{"code": 200, "status": "ok", "certificate": certificate.PEM})
+		return string(encoded)
+	}
+	listvs := `{"code": 200, "status": "ok", "VS": [
+		{ "Index": 1, "CertFile": "other old", "SubVS": [ { "VSIndex": 3, "CertFile": "old" } ] },
+		{ "Index": 2, "CertFile": "unrelated" },
+		{ "Index": 3, "MasterVSID": 1, "CertFile": "old" }
+	]}`
+
+	t.Run("success", func(t *testing.T) {
+		server := createCommandServer(map[string][]string{
+			"readcert": {show(old), show(renewed)},
+			"addcert":  {`{"code": 200, "status": "ok"}`},
+			"listvs":   {listvs},
+			"modvs":    {`{"code": 200, "status": "ok"}`},
+			"delcert":  {`{"code": 200, "status": "ok"}`},
+		})
+		defer server.Close()
+		client := createClientForUnit(server.Server, "baz")
+
+		data := base64.StdEncoding.EncodeToString([]byte(renewed.PEM + renewed.keyPEM(t)))
+		response, err := client.RotateCertificate("old", "new", nil, data)
+		require.NoError(t, err)
+		assert.Equal(t, []int32{1}, response.VirtualServices)
+		assert.Equal(t, []int32{3}, response.SubVirtualServices)
+		assert.Equal(t, []string{"readcert", "addcert", "readcert", "listvs", "modvs", "modvs", "delcert"}, server.Commands)
+		assert.Equal(t, "other new", server.Requests[4]["CertFile"])
+		assert.Equal(t, "3", server.Requests[5]["vs"])
+		assert.Equal(t, "old", server.Requests[6]["cert"])
+	})
+
+	t.Run("key mismatch", func(t *testing.T) {
+		server := createCommandServer(map[string][]string{
+			"readcert": {show(old)},
+		})
+		defer server.Close()
+		client := createClientForUnit(server.Server, "baz")
+
+		_, err := client.RotateCertificate("old", "new", nil, renewed.PEM+old.keyPEM(t))
+		assert.ErrorContains(t, err, "private key does not match")
+		assert.Equal(t, []string{"readcert"}, server.Commands)
+	})
+
+	t.Run("pkcs12", func(t *testing.T) {
+		server := createCommandServer(map[string][]string{
+			"readcert": {show(old), show(renewed)},
+			"addcert":  {`{"code": 200, "status": "ok"}`},
+			"listvs":   {listvs},
+			"modvs":    {`{"code": 200, "status": "ok"}`},
+			"delcert":  {`{"code": 200, "status": "ok"}`},
+		})
+		defer server.Close()
+		client := createClientForUnit(server.Server, "baz")
+
+		pfx, err := pkcs12.Modern.Encode(renewed.Key, renewed.Certificate, nil, "secret")
+		require.NoError(t, err)
+		data := base64.StdEncoding.EncodeToString(pfx)
+
+		_, err = client.RotateCertificate("old", "new", convert2Ptr("wrong"), data)
+		assert.ErrorContains(t, err, "pkcs12")
+
+		_, err = client.RotateCertificate("old", "new", convert2Ptr("secret"), data)
+		require.NoError(t, err)
+		assert.Equal(t, "secret", server.Requests[2]["password"])
+	})
+
+	t.Run("pkcs12 key mismatch", func(t *testing.T) {
+		server := createCommandServer(map[string][]string{
+			"readcert": {show(old)},
+		})
+		defer server.Close()
+		client := createClientForUnit(server.Server, "baz")
+
+		pfx, err := pkcs12.Modern.Encode(old.Key, renewed.Certificate, nil, "secret")
+		require.NoError(t, err)
+
+		_, err = client.RotateCertificate("old", "new", convert2Ptr("secret"), base64.StdEncoding.EncodeToString(pfx))
+		assert.ErrorContains(t, err, "private key does not match")
+		assert.Equal(t, []string{"readcert"}, server.Commands)
+	})
+
+	t.Run("hostnames not covered", func(t *testing.T) {
+		server := createCommandServer(map[string][]string{
+			"readcert": {show(old)},
+		})
+		defer server.Close()
+		client := createClientForUnit(server.Server, "baz")
+
+		_, err := client.RotateCertificate("old", "new", nil, narrow.PEM+narrow.keyPEM(t))
+		assert.ErrorContains(t, err, "www.example.com")
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		server := createCommandServer(map[string][]string{
+			"readcert": {show(old), show(renewed)},
+			"addcert":  {`{"code": 200, "status": "ok"}`},
+			"listvs":   {listvs},
+			"modvs":    {`{"code": 200, "status": "ok"}`, `!{"code": 400, "status": "fail", "message": "failed"}`, `{"code": 200, "status": "ok"}`},
+			"delcert":  {`{"code": 200, "status": "ok"}`},
+		})
+		defer server.Close()
+		client := createClientForUnit(server.Server, "baz")
+
+		_, err := client.RotateCertificate("old", "new", nil, renewed.PEM+renewed.keyPEM(t))
+		assert.Error(t, err)
+		assert.Equal(t, []string{"readcert", "addcert", "readcert", "listvs", "modvs", "modvs", "modvs", "delcert"}, server.Commands)
+		assert.Equal(t, "other old", server.Requests[6]["CertFile"])
+		assert.Equal(t, "new", server.Requests[7]["cert"])
+	})
+}
+
+func TestParsedCertificate_MissingHostnames(t *testing.T) {
+	not_after := time.Now().Add(90 * 24 * time.Hour)
+	legacy := newParsedCertificate(createTestCertificate(t, "legacy.example.com", nil, not_after, nil).Certificate)
+	renewed := newParsedCertificate(createTestCertificate(t, "legacy.example.com", nil, not_after, nil).Certificate)
+	san := newParsedCertificate(createTestCertificate(t, "other", []string{"legacy.example.com"}, not_after, nil).Certificate)
+	other := newParsedCertificate(createTestCertificate(t, "other.example.com", nil, not_after, nil).Certificate)
+
+	assert.Empty(t, renewed.missingHostnames(legacy))
+	assert.Empty(t, san.missingHostnames(legacy))
+	assert.Equal(t, []string{"legacy.example.com"}, other.missingHostnames(legacy))
+}
@@ -0,0 +1,254 @@
+package api
+
+import (
+	"crypto"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+type UploadCertificateResponse struct {
+	*LoadMasterResponse
+	Certificate ParsedCertificate
+	// Intermediates holds the names of the intermediate certificates of the chain, including already installed ones.
+	Intermediates []string
+}
+
+func encodeCertificatePEM(certificates ...*x509.Certificate) []byte {
+	var encoded []byte
+	for _, certificate := range certificates {
+		encoded = append(encoded, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certificate.Raw})...)
+	}
+
+	return encoded
+}
+
+func encodePrivateKeyPEM(key crypto.Signer) ([]byte, error) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), nil
+}
+
+func keyMatchesCertificate(certificate *x509.Certificate, key crypto.Signer) bool {
+	public_key, ok := certificate.PublicKey.(interface{ Equal(crypto.PublicKey) bool })
+	return ok && public_key.Equal(key.Public())
+}
+
+// encodeCertificateUpload encodes the certificate and its key for the upload. With a password they are packed into
+// a PKCS#12 container protected by the password, otherwise the key is encoded as unencrypted PKCS#8 PEM.
+func encodeCertificateUpload(certificate *x509.Certificate, key crypto.Signer, password *string) ([]byte, error) {
+	if password != nil {
+		return pkcs12.Modern.Encode(key, certificate, nil, *password)
+	}
+
+	key_pem, err := encodePrivateKeyPEM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(encodeCertificatePEM(certificate), key_pem...), nil
+}
+
+// AddTLSCertificate uploads the leaf and key of the certificate and the intermediates of its chain.
+func (c *Client) AddTLSCertificate(name string, certificate tls.Certificate, password *string) (*UploadCertificateResponse, error) {
+	slog.Debug("Adding tls certificate", "name", name)
+
+	var chain []*x509.Certificate
+	for _, der := range certificate.Certificate {
+		parsed, err := x509.ParseCertificate(der)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse certificate: %w", err)
+		}
+		chain = append(chain, parsed)
+	}
+
+	key, ok := certificate.PrivateKey.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("private key of type %T is not supported", certificate.PrivateKey)
+	}
+
+	return c.AddX509Certificate(name, chain, key, password)
+}
+
+// AddX509Certificate uploads the first certificate of the chain with its key. If a password is given, both are
+// uploaded as a PKCS#12 container protected by the password, otherwise the key is uploaded unencrypted.
+// The remaining certificates except self-signed roots are uploaded as intermediate certificates named
+// "<name>_intermediate<n>" with the first n not taken by an installed intermediate, unless they are installed already.
+func (c *Client) AddX509Certificate(name string, chain []*x509.Certificate, key crypto.Signer, password *string) (*UploadCertificateResponse, error) {
+	slog.Debug("Adding x509 certificate", "name", name)
+
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("certificate chain is empty")
+	}
+	if !keyMatchesCertificate(chain[0], key) {
+		return nil, fmt.Errorf("private key does not match certificate %s", chain[0].Subject)
+	}
+
+	upload, err := encodeCertificateUpload(chain[0], key, password)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.addCertificateChain(name, chain[0], upload, password, chain[1:])
+}
+
+// AddPEMCertificate uploads PEM data containing a certificate, its private key and optionally its chain.
+// An encrypted private key is uploaded as is and decrypted by the LoadMaster with the password, an unencrypted key
+// is protected with the password as in AddX509Certificate.
+// Chain certificates are uploaded as in AddX509Certificate.
+func (c *Client) AddPEMCertificate(name string, data []byte, password *string) (*UploadCertificateResponse, error) {
+	slog.Debug("Adding pem certificate", "name", name)
+
+	var chain []*x509.Certificate
+	var key []byte
+	for rest := data; ; {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		switch {
+		case block.Type == "CERTIFICATE":
+			certificate, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse certificate: %w", err)
+			}
+			chain = append(chain, certificate)
+		case strings.HasSuffix(block.Type, "PRIVATE KEY"):
+			key = pem.EncodeToMemory(block)
+		}
+	}
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("no certificate found in data")
+	}
+	if key == nil {
+		return nil, fmt.Errorf("no private key found in data")
+	}
+
+	upload := append(encodeCertificatePEM(chain[0]), key...)
+	if err := verifyCertificateKey(upload); err != nil {
+		return nil, err
+	}
+	if password != nil && !encryptedPEM(key) {
+		pair, err := tls.X509KeyPair(encodeCertificatePEM(chain[0]), key)
+		if err != nil {
+			return nil, err
+		}
+		signer, ok := pair.PrivateKey.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("private key of type %T is not supported", pair.PrivateKey)
+		}
+		upload, err = encodeCertificateUpload(chain[0], signer, password)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return c.addCertificateChain(name, chain[0], upload, password, chain[1:])
+}
+
+// AddPKCS12Certificate decodes a PKCS#12 file with the password and uploads its certificate, key and
+// intermediates as in AddX509Certificate, so the key stays protected by the password.
+func (c *Client) AddPKCS12Certificate(name string, data []byte, password *string) (*UploadCertificateResponse, error) {
+	slog.Debug("Adding pkcs12 certificate", "name", name)
+
+	var decode_password string
+	if password != nil {
+		decode_password = *password
+	}
+	key, certificate, ca_certificates, err := pkcs12.DecodeChain(data, decode_password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode pkcs12 data: %w", err)
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("private key of type %T is not supported", key)
+	}
+
+	return c.AddX509Certificate(name, append([]*x509.Certificate{certificate}, ca_certificates...), signer, password)
+}
+
+// nextIntermediateName returns the first name "<name>_intermediate<n>" which is not taken.
+func nextIntermediateName(name string, taken []string) string {
+	for n := 1; ; n++ {
+		candidate := name + "_intermediate" + strconv.Itoa(n)
+		if !slices.Contains(taken, candidate) {
+			return candidate
+		}
+	}
+}
+
+// installedIntermediates returns the names of the installed intermediate certificates by their SHA-256 fingerprint.
+func (c *Client) installedIntermediates() (map[string]string, error) {
+	intermediates, err := c.listParsedCertificateType(true)
+	if err != nil {
+		return nil, err
+	}
+
+	installed := map[string]string{}
+	for _, intermediate := range intermediates {
+		installed[intermediate.SHA256Fingerprint] = intermediate.Name
+	}
+
+	return installed, nil
+}
+
+func (c *Client) addCertificateChain(name string, leaf *x509.Certificate, data []byte, password *string, intermediates []*x509.Certificate) (*UploadCertificateResponse, error) {
+	var undo rollback
+
+	installed, err := c.installedIntermediates()
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := c.AddCertificate(name, password, base64.StdEncoding.EncodeToString(data))
+	if err != nil {
+		return nil, err
+	}
+	undo.push(func() error {
+		_, err := c.DeleteCertificate(name)
+		return err
+	})
+
+	result := &UploadCertificateResponse{LoadMasterResponse: response, Certificate: newParsedCertificate(leaf)}
+	result.Certificate.Name = name
+	taken := slices.Collect(maps.Values(installed))
+	for _, intermediate := range intermediates {
+		if intermediate.CheckSignatureFrom(intermediate) == nil {
+			continue
+		}
+
+		parsed := newParsedCertificate(intermediate)
+		if existing, ok := installed[parsed.SHA256Fingerprint]; ok {
+			result.Intermediates = append(result.Intermediates, existing)
+			continue
+		}
+
+		intermediate_name := nextIntermediateName(name, taken)
+		taken = append(taken, intermediate_name)
+		_, err := c.AddIntermediateCertificate(intermediate_name, base64.StdEncoding.EncodeToString(encodeCertificatePEM(intermediate)))
+		if err != nil {
+			return nil, undo.run(err)
+		}
+		undo.push(func() error {
+			_, err := c.DeleteIntermediateCertificate(intermediate_name)
+			return err
+		})
+		result.Intermediates = append(result.Intermediates, intermediate_name)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,160 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+func TestClient_AddTLSCertificate(t *testing.T) {
+	not_after := time.Now().Add(90 * 24 * time.Hour)
+	root := createTestCertificate(t, "Root CA", nil, not_after, nil)
+	intermediate := createTestCertificate(t, "Intermediate CA", nil, not_after, root)
+	leaf := createTestCertificate(t, "example.com", []string{"example.com"}, not_after, intermediate)
+
+	server := createCommandServer(map[string][]string{
+		"listintermediate": {`{"code": 200, "status": "ok", "cert": []}`},
+		"addcert":          {`{"code": 200, "status": "ok"}`},
+		"addintermediate":  {`{"code": 200, "status": "ok"}`},
+	})
+	defer server.Close()
+	client := createClientForUnit(server.Server, "baz")
+
+	certificate := tls.Certificate{
+		Certificate: [][]byte{leaf.Certificate.Raw, intermediate.Certificate.Raw, root.Certificate.Raw},
+		PrivateKey:  leaf.Key,
+	}
+	response, err := client.AddTLSCertificate("example", certificate, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "example", response.Certificate.Name)
+	assert.Equal(t, []string{"example_intermediate1"}, response.Intermediates)
+	assert.Equal(t, []string{"listintermediate", "addcert", "addintermediate"}, server.Commands)
+
+	uploaded, err := base64.StdEncoding.DecodeString(server.Requests[1]["data"].(string))
+	require.NoError(t, err)
+	assert.NoError(t, verifyCertificateKey(uploaded))
+	assert.Equal(t, 1, strings.Count(string(uploaded), "BEGIN CERTIFICATE"))
+
+	intermediate_data, err := base64.StdEncoding.DecodeString(server.Requests[2]["data"].(string))
+	require.NoError(t, err)
+	assert.Equal(t, intermediate.PEM, string(intermediate_data))
+}
+
+func TestClient_AddPEMCertificate(t *testing.T) {
+	not_after := time.Now().Add(90 * 24 * time.Hour)
+	root := createTestCertificate(t, "Root CA", nil, not_after, nil)
+	intermediate := createTestCertificate(t, "Intermediate CA", nil, not_after, root)
+	leaf := createTestCertificate(t, "example.com", []string{"example.com"}, not_after, intermediate)
+	show, _ := json.Marshal(map[string]any{"code": 200, "status": "ok", "certificate": intermediate.PEM})
+
+	t.Run("existing intermediate", func(t *testing.T) {
+		server := createCommandServer(map[string][]string{
+			"listintermediate": {`{"code": 200, "status": "ok", "cert": [ { "name": "installed" } ]}`},
+			"readintermediate": {string(show)},
+			"addcert":          {`{"code": 200, "status": "ok"}`},
+		})
+		defer server.Close()
+		client := createClientForUnit(server.Server, "baz")
+
+		response, err := client.AddPEMCertificate("example", []byte(leaf.PEM+leaf.keyPEM(t)+intermediate.PEM), nil)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"installed"}, response.Intermediates)
+		assert.Equal(t, []string{"listintermediate", "readintermediate", "addcert"}, server.Commands)
+	})
+
+	t.Run("password", func(t *testing.T) {
+		server := createCommandServer(map[string][]string{
+			"listintermediate": {`{"code": 200, "status": "ok", "cert": [ { "name": "installed" } ]}`},
+			"readintermediate": {string(show)},
+			"addcert":          {`{"code": 200, "status": "ok"}`},
+		})
+		defer server.Close()
+		client := createClientForUnit(server.Server, "baz")
+
+		_, err := client.AddPEMCertificate("example", []byte(leaf.PEM+leaf.keyPEM(t)), convert2Ptr("secret"))
+		require.NoError(t, err)
+		assertProtectedUpload(t, server.Requests[2]["data"].(string), "secret")
+	})
+
+	t.Run("mismatching key", func(t *testing.T) {
+		server := createCommandServer(map[string][]string{})
+		defer server.Close()
+		client := createClientForUnit(server.Server, "baz")
+
+		_, err := client.AddPEMCertificate("example", []byte(leaf.PEM+intermediate.keyPEM(t)), nil)
+		assert.ErrorContains(t, err, "does not match")
+		assert.Empty(t, server.Commands)
+	})
+}
+
+// assertProtectedUpload checks that the upload is a PKCS#12 container which can only be decoded with the password.
+func assertProtectedUpload(t *testing.T, data string, password string) {
+	t.Helper()
+
+	uploaded, err := base64.StdEncoding.DecodeString(data)
+	require.NoError(t, err)
+	assert.NotContains(t, string(uploaded), "PRIVATE KEY")
+
+	_, _, _, err = pkcs12.DecodeChain(uploaded, "wrong")
+	assert.Error(t, err)
+	key, _, _, err := pkcs12.DecodeChain(uploaded, password)
+	require.NoError(t, err)
+	assert.NotNil(t, key)
+}
+
+func TestClient_AddX509CertificateWithPassword(t *testing.T) {
+	leaf := createTestCertificate(t, "example.com", []string{"example.com"}, time.Now().Add(time.Hour), nil)
+
+	server := createCommandServer(map[string][]string{
+		"listintermediate": {`{"code": 200, "status": "ok", "cert": []}`},
+		"addcert":          {`{"code": 200, "status": "ok"}`},
+	})
+	defer server.Close()
+	client := createClientForUnit(server.Server, "baz")
+
+	_, err := client.AddX509Certificate("example", []*x509.Certificate{leaf.Certificate}, leaf.Key, convert2Ptr("secret"))
+	require.NoError(t, err)
+	assert.Equal(t, "secret", server.Requests[1]["password"])
+
+	assertProtectedUpload(t, server.Requests[1]["data"].(string), "secret")
+
+	_, err = client.AddX509Certificate("example", []*x509.Certificate{leaf.Certificate}, createTestCertificate(t, "other", nil, time.Now().Add(time.Hour), nil).Key, nil)
+	assert.Error(t, err)
+}
+
+func TestClient_AddPKCS12Certificate(t *testing.T) {
+	not_after := time.Now().Add(90 * 24 * time.Hour)
+	root := createTestCertificate(t, "Root CA", nil, not_after, nil)
+	intermediate := createTestCertificate(t, "Intermediate CA", nil, not_after, root)
+	leaf := createTestCertificate(t, "example.com", []string{"example.com"}, not_after, intermediate)
+
+	data, err := pkcs12.Modern.Encode(leaf.Key, leaf.Certificate, []*x509.Certificate{intermediate.Certificate, root.Certificate}, "secret")
+	require.NoError(t, err)
+
+	server := createCommandServer(map[string][]string{
+		"listintermediate": {`{"code": 200, "status": "ok", "cert": []}`},
+		"addcert":          {`{"code": 200, "status": "ok"}`},
+		"addintermediate":  {`{"code": 200, "status": "ok"}`},
+	})
+	defer server.Close()
+	client := createClientForUnit(server.Server, "baz")
+
+	response, err := client.AddPKCS12Certificate("example", data, convert2Ptr("secret"))
+	require.NoError(t, err)
+	assert.Equal(t, "example.com", response.Certificate.CommonName)
+	assert.Equal(t, []string{"example_intermediate1"}, response.Intermediates)
+	assert.Equal(t, []string{"listintermediate", "addcert", "addintermediate"}, server.Commands)
+
+	assertProtectedUpload(t, server.Requests[1]["data"].(string), "secret")
+
+	_, err = client.AddPKCS12Certificate("example", data, convert2Ptr("wrong"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,260 @@
+package api
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type TLSProtocol string
+
+const (
+	TLSProtocolSSLv3 TLSProtocol = "SSLv3"
+	TLSProtocolTLS10 TLSProtocol = "TLS1.0"
+	TLSProtocolTLS11 TLSProtocol = "TLS1.1"
+	TLSProtocolTLS12 TLSProtocol = "TLS1.2"
+	TLSProtocolTLS13 TLSProtocol = "TLS1.3"
+)
+
+// tlsTypeBits lists the protocols in the bit order of TLSType.
+var tlsTypeBits = []TLSProtocol{TLSProtocolSSLv3, TLSProtocolTLS10, TLSProtocolTLS11, TLSProtocolTLS12, TLSProtocolTLS13}
+
+type CipherSet struct {
+	Name    string
+	Ciphers []string
+}
+
+type ListCipherSetResponse struct {
+	*LoadMasterResponse
+	CipherSets []string `json:"cipherset"`
+}
+
+type CipherSetResponse struct {
+	*LoadMasterResponse
+	// Data holds the colon separated ciphers of the set.
+	Data string `json:"cipherset"`
+}
+
+func (r *CipherSetResponse) Ciphers() []string {
+	return splitCiphers(r.Data)
+}
+
+func splitCiphers(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return r == ':' || r == ' ' || r == ','
+	})
+}
+
+// EnabledTLSProtocols decodes the TLSType of a virtual service. TLSType is a bitmask of the disabled protocols,
+// starting with SSLv3 at bit 1. An empty value enables every protocol but SSLv3.
+func EnabledTLSProtocols(tls_type string) ([]TLSProtocol, error) {
+	if tls_type == "" {
+		return tlsTypeBits[1:], nil
+	}
+
+	disabled, err := strconv.Atoi(tls_type)
+	if err != nil || disabled < 0 || disabled >= 1<<len(tlsTypeBits) {
+		return nil, fmt.Errorf("invalid TLSType %q", tls_type)
+	}
+
+	var enabled []TLSProtocol
+	for bit, protocol := range tlsTypeBits {
+		if disabled&(1<<bit) == 0 {
+			enabled = append(enabled, protocol)
+		}
+	}
+
+	return enabled, nil
+}
+
+// cipherProtocols returns the protocols a cipher can be negotiated with, based on its OpenSSL or IANA name.
+// IANA names of TLS 1.3 suites such as TLS_AES_128_GCM_SHA256 lack the key exchange and "_WITH_" part,
+// other IANA names are classified by the cipher following "_WITH_".
+func cipherProtocols(cipher string) []TLSProtocol {
+	if strings.HasPrefix(cipher, "TLS_") {
+		_, suite, found := strings.Cut(cipher, "_WITH_")
+		if !found {
+			return []TLSProtocol{TLSProtocolTLS13}
+		}
+		cipher = suite
+	}
+
+	switch {
+	case strings.Contains(cipher, "GCM"), strings.Contains(cipher, "CHACHA20"), strings.Contains(cipher, "CCM"), strings.HasSuffix(cipher, "SHA256"), strings.HasSuffix(cipher, "SHA384"):
+		return []TLSProtocol{TLSProtocolTLS12}
+	}
+
+	return []TLSProtocol{TLSProtocolSSLv3, TLSProtocolTLS10, TLSProtocolTLS11, TLSProtocolTLS12}
+}
+
+// ValidateCiphers checks that every cipher can be used with one of the protocols enabled by the TLSType.
+// TLS 1.3 cipher suites are only valid in a TLS 1.3 cipher set and vice versa.
+func ValidateCiphers(tls_type string, ciphers []string, tls13 bool) error {
+	enabled, err := EnabledTLSProtocols(tls_type)
+	if err != nil {
+		return err
+	}
+
+	for _, cipher := range ciphers {
+		protocols := cipherProtocols(cipher)
+		if slices.Contains(protocols, TLSProtocolTLS13) != tls13 {
+			if tls13 {
+				return fmt.Errorf("cipher %s is not a TLS 1.3 cipher suite", cipher)
+			}
+			return fmt.Errorf("cipher %s is a TLS 1.3 cipher suite, use Tls13CipherSet instead", cipher)
+		}
+		if !slices.ContainsFunc(protocols, func(protocol TLSProtocol) bool { return slices.Contains(enabled, protocol) }) {
+			return fmt.Errorf("cipher %s requires one of %v, but TLSType %q only enables %v", cipher, protocols, tls_type, enabled)
+		}
+	}
+
+	return nil
+}
+
+func (c *Client) ListCipherSet() (*ListCipherSetResponse, error) {
+	slog.Debug("Listing cipher sets")
+	payload := struct {
+		*LoadMasterRequest
+	}{
+		LoadMasterRequest: &LoadMasterRequest{
+			Command: "listcipherset",
+		},
+	}
+
+	response, err := sendRequest(c, payload, ListCipherSetResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (c *Client) ShowCipherSet(name string) (*CipherSetResponse, error) {
+	slog.Debug("Showing cipher set", "name", name)
+	payload := struct {
+		*LoadMasterRequest
+		Name string `json:"name"`
+	}{
+		LoadMasterRequest: &LoadMasterRequest{
+			Command: "getcipherset",
+		},
+		Name: name,
+	}
+
+	response, err := sendRequest(c, payload, CipherSetResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// AddCipherSet creates a custom cipher set. It fails if a set with the name exists already.
+func (c *Client) AddCipherSet(cipher_set CipherSet) (*CipherSetResponse, error) {
+	slog.Debug("Adding cipher set", "name", cipher_set.Name)
+
+	list, err := c.ListCipherSet()
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(list.CipherSets, cipher_set.Name) {
+		return nil, fmt.Errorf("cipher set %s exists already", cipher_set.Name)
+	}
+
+	return c.ModifyCipherSet(cipher_set)
+}
+
+// ModifyCipherSet replaces the ciphers of a custom cipher set, creating it if it does not exist.
+func (c *Client) ModifyCipherSet(cipher_set CipherSet) (*CipherSetResponse, error) {
+	slog.Debug("Modifying cipher set", "name", cipher_set.Name)
+	if len(cipher_set.Ciphers) == 0 {
+		return nil, fmt.Errorf("cipher set %s contains no ciphers", cipher_set.Name)
+	}
+
+	payload := struct {
+		*LoadMasterRequest
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}{
+		LoadMasterRequest: &LoadMasterRequest{
+			Command: "modifycipherset",
+		},
+		Name:  cipher_set.Name,
+		Value: strings.Join(cipher_set.Ciphers, ":"),
+	}
+
+	response, err := sendRequest(c, payload, CipherSetResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (c *Client) DeleteCipherSet(name string) (*LoadMasterResponse, error) {
+	slog.Debug("Deleting cipher set", "name", name)
+	payload := struct {
+		*LoadMasterRequest
+		Name string `json:"name"`
+	}{
+		LoadMasterRequest: &LoadMasterRequest{
+			Command: "delcipherset",
+		},
+		Name: name,
+	}
+
+	response, err := sendRequest(c, payload, LoadMasterResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// ValidateCipherSets checks that CipherSet and Tls13CipherSet reference existing cipher sets and that
+// these sets as well as Ciphers only contain ciphers supported by the TLSType.
+func (c *Client) ValidateCipherSets(properties VirtualServiceParametersSSLProperties) error {
+	slog.Debug("Validating cipher sets", "cipher_set", properties.CipherSet, "tls13_cipher_set", properties.Tls13CipherSet)
+
+	list, err := c.ListCipherSet()
+	if err != nil {
+		return err
+	}
+
+	for _, reference := range []struct {
+		name  string
+		tls13 bool
+	}{{properties.CipherSet, false}, {properties.Tls13CipherSet, true}} {
+		if reference.name == "" {
+			continue
+		}
+		if !slices.Contains(list.CipherSets, reference.name) {
+			return fmt.Errorf("cipher set %s does not exist", reference.name)
+		}
+
+		cipher_set, err := c.ShowCipherSet(reference.name)
+		if err != nil {
+			return err
+		}
+		if err := ValidateCiphers(properties.TLSType, cipher_set.Ciphers(), reference.tls13); err != nil {
+			return fmt.Errorf("cipher set %s: %w", reference.name, err)
+		}
+	}
+
+	return ValidateCiphers(properties.TLSType, splitCiphers(properties.Ciphers), false)
+}
+
+// ValidateVirtualServiceCipherSets runs ValidateCipherSets on the SSL properties of the virtual service.
+func (c *Client) ValidateVirtualServiceCipherSets(vs_identifier string) error {
+	response, err := c.ShowVirtualService(vs_identifier)
+	if err != nil {
+		return err
+	}
+	if response.VirtualService == nil || response.VirtualServiceParameters == nil || response.VirtualServiceParametersSSLProperties == nil {
+		return nil
+	}
+
+	return c.ValidateCipherSets(*response.VirtualServiceParametersSSLProperties)
+}
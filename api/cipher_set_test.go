@@ -0,0 +1,105 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnabledTLSProtocols(t *testing.T) {
+	enabled, err := EnabledTLSProtocols("")
+	require.NoError(t, err)
+	assert.Equal(t, []TLSProtocol{TLSProtocolTLS10, TLSProtocolTLS11, TLSProtocolTLS12, TLSProtocolTLS13}, enabled)
+
+	enabled, err = EnabledTLSProtocols("7")
+	require.NoError(t, err)
+	assert.Equal(t, []TLSProtocol{TLSProtocolTLS12, TLSProtocolTLS13}, enabled)
+
+	_, err = EnabledTLSProtocols("32")
+	assert.Error(t, err)
+}
+
+func TestValidateCiphers(t *testing.T) {
+	testCases := []struct {
+		name     string
+		tls_type string
+		ciphers  []string
+		tls13    bool
+		wantErr  bool
+	}{
+		{"tls 1.2 ciphers", "7", []string{"ECDHE-RSA-AES128-GCM-SHA256", "ECDHE-RSA-AES256-SHA384"}, false, false},
+		{"legacy cipher with tls 1.2", "7", []string{"AES128-SHA"}, false, false},
+		{"gcm cipher with tls 1.1 only", "25", []string{"ECDHE-RSA-AES128-GCM-SHA256"}, false, true},
+		{"iana tls 1.2 ciphers", "7", []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", "TLS_RSA_WITH_AES_256_CBC_SHA256"}, false, false},
+		{"iana legacy cipher with tls 1.1 only", "25", []string{"TLS_RSA_WITH_AES_128_CBC_SHA"}, false, false},
+		{"iana gcm cipher with tls 1.1 only", "25", []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"}, false, true},
+		{"iana tls 1.2 cipher in tls 1.3 set", "", []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"}, true, true},
+		{"tls 1.3 suite in cipher set", "", []string{"TLS_AES_128_GCM_SHA256"}, false, true},
+		{"tls 1.3 suites", "15", []string{"TLS_AES_128_GCM_SHA256", "TLS_CHACHA20_POLY1305_SHA256"}, true, false},
+		{"tls 1.3 disabled", "16", []string{"TLS_AES_128_GCM_SHA256"}, true, true},
+		{"tls 1.2 cipher in tls 1.3 set", "", []string{"AES128-SHA"}, true, true},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCiphers(tt.tls_type, tt.ciphers, tt.tls13)
+			assert.Equal(t, tt.wantErr, err != nil, "error = %v", err)
+		})
+	}
+}
+
+func TestClient_CipherSet(t *testing.T) {
+	server := createCommandServer(map[string][]string{
+		"listcipherset":   {`{"code": 200, "status": "ok", "cipherset": ["Default", "custom"]}`},
+		"getcipherset":    {`{"code": 200, "status": "ok", "cipherset": "ECDHE-RSA-AES128-GCM-SHA256:AES128-SHA"}`},
+		"modifycipherset": {`{"code": 200, "status": "ok"}`},
+	})
+	defer server.Close()
+	client := createClientForUnit(server.Server, "baz")
+
+	response, err := client.ShowCipherSet("custom")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"ECDHE-RSA-AES128-GCM-SHA256", "AES128-SHA"}, response.Ciphers())
+
+	_, err = client.AddCipherSet(CipherSet{Name: "custom", Ciphers: []string{"AES128-SHA"}})
+	assert.ErrorContains(t, err, "exists already")
+
+	_, err = client.AddCipherSet(CipherSet{Name: "new", Ciphers: []string{"AES128-SHA", "AES256-SHA"}})
+	require.NoError(t, err)
+	assert.Equal(t, "AES128-SHA:AES256-SHA", server.Requests[len(server.Requests)-1]["value"])
+
+	_, err = client.ModifyCipherSet(CipherSet{Name: "new"})
+	assert.Error(t, err)
+}
+
+func TestClient_ValidateVirtualServiceCipherSets(t *testing.T) {
+	testCases := []struct {
+		name      string
+		vs        string
+		cipherset string
+		wantErr   string
+	}{
+		{"valid", `{"code": 200, "status": "ok", "Index": 1, "CipherSet": "custom", "TLSType": "7"}`, "ECDHE-RSA-AES128-GCM-SHA256", ""},
+		{"unknown set", `{"code": 200, "status": "ok", "Index": 1, "CipherSet": "missing"}`, "", "does not exist"},
+		{"unsupported protocol", `{"code": 200, "status": "ok", "Index": 1, "CipherSet": "custom", "TLSType": "25"}`, "ECDHE-RSA-AES128-GCM-SHA256", "requires one of"},
+		{"tls 1.3 disabled", `{"code": 200, "status": "ok", "Index": 1, "Tls13CipherSet": "tls13", "TLSType": "16"}`, "TLS_AES_128_GCM_SHA256", "TLS1.3"},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			server := createCommandServer(map[string][]string{
+				"showvs":        {tt.vs},
+				"listcipherset": {`{"code": 200, "status": "ok", "cipherset": ["custom", "tls13"]}`},
+				"getcipherset":  {`{"code": 200, "status": "ok", "cipherset": "` + tt.cipherset + `"}`},
+			})
+			defer server.Close()
+			client := createClientForUnit(server.Server, "baz")
+
+			err := client.ValidateVirtualServiceCipherSets("1")
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tt.wantErr)
+			}
+		})
+	}
+}
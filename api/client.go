@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -209,3 +210,21 @@ func (r LoadMasterRequest) LogValue() slog.Value {
 		slog.String("ApiKey", "[redacted]"),
 	)
 }
+
+type rollback []func() error
+
+func (r *rollback) push(undo func() error) {
+	*r = append(*r, undo)
+}
+
+// run reverts all registered steps in reverse order and returns err joined with any error raised while reverting.
+func (r rollback) run(err error) error {
+	errs := []error{err}
+	for i := len(r) - 1; i >= 0; i-- {
+		if undo_err := r[i](); undo_err != nil {
+			errs = append(errs, fmt.Errorf("rollback failed: %w", undo_err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
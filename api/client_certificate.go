@@ -0,0 +1,169 @@
+package api
+
+import (
+	"encoding/base64"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ClientCertMode selects whether a virtual service requests client certificates and how they are passed to the real servers.
+type ClientCertMode int32
+
+const (
+	ClientCertNone                   ClientCertMode = 0
+	ClientCertRequired               ClientCertMode = 1
+	ClientCertAddHeaders             ClientCertMode = 2
+	ClientCertPassDERAsSSLClientCert ClientCertMode = 3
+	ClientCertPassDERAsXCert         ClientCertMode = 4
+	ClientCertPassPEMAsSSLClientCert ClientCertMode = 5
+	ClientCertPassPEMAsXCert         ClientCertMode = 6
+)
+
+func (m ClientCertMode) Valid() bool {
+	return m >= ClientCertNone && m <= ClientCertPassPEMAsXCert
+}
+
+func (m ClientCertMode) Required() bool {
+	return m != ClientCertNone
+}
+
+type MutualTLSOptions struct {
+	// CACertificates holds the names of the installed client CA certificates trusted by the virtual service.
+	CACertificates []string
+	// Mode must require client certificates, it defaults to ClientCertRequired.
+	Mode       ClientCertMode
+	OCSPVerify *bool
+}
+
+type ClientCAInUseError struct {
+	Name            string
+	VirtualServices []int32
+}
+
+func (e *ClientCAInUseError) Error() string {
+	return fmt.Sprintf("client CA certificate %s is used by virtual services %v", e.Name, e.VirtualServices)
+}
+
+// ListClientCACertificate returns the installed intermediate certificates which are CA certificates.
+// The LoadMaster verifies client certificates against the intermediate certificates of the virtual service.
+func (c *Client) ListClientCACertificate() ([]ParsedCertificate, error) {
+	slog.Debug("Listing client CA certificates")
+
+	intermediates, err := c.listParsedCertificateType(true)
+	if err != nil {
+		return nil, err
+	}
+
+	var certificates []ParsedCertificate
+	for _, intermediate := range intermediates {
+		if intermediate.X509 != nil && intermediate.X509.IsCA {
+			certificates = append(certificates, intermediate)
+		}
+	}
+
+	return certificates, nil
+}
+
+// AddClientCACertificate uploads a PEM or DER encoded CA certificate used to verify client certificates.
+func (c *Client) AddClientCACertificate(name string, data []byte) (*LoadMasterResponse, error) {
+	slog.Debug("Adding client CA certificate", "name", name)
+
+	certificates, err := ParseCertificateData(string(data))
+	if err != nil {
+		return nil, err
+	}
+	if len(certificates) != 1 {
+		return nil, fmt.Errorf("expected one certificate, found %d", len(certificates))
+	}
+	if !certificates[0].X509.IsCA {
+		return nil, fmt.Errorf("certificate %s is not a CA certificate", certificates[0].Subject)
+	}
+
+	return c.AddIntermediateCertificate(name, base64.StdEncoding.EncodeToString(encodeCertificatePEM(certificates[0].X509)))
+}
+
+// DeleteClientCACertificate removes a client CA certificate. It fails with a ClientCAInUseError if a virtual service
+// requiring client certificates still trusts it.
+func (c *Client) DeleteClientCACertificate(name string) (*LoadMasterResponse, error) {
+	slog.Debug("Deleting client CA certificate", "name", name)
+
+	services, err := c.ListVirtualService()
+	if err != nil {
+		return nil, err
+	}
+
+	var users []int32
+	for i := range services.VS {
+		vs := &services.VS[i]
+		if vs.clientCertMode().Required() && slices.Contains(vs.intermediateCertificateNames(), name) {
+			users = append(users, vs.Index)
+		}
+	}
+	if len(users) > 0 {
+		return nil, &ClientCAInUseError{Name: name, VirtualServices: users}
+	}
+
+	return c.DeleteIntermediateCertificate(name)
+}
+
+func (vs *VirtualService) clientCertMode() ClientCertMode {
+	if vs.VirtualServiceParameters == nil || vs.VirtualServiceParametersSSLProperties == nil || vs.ClientCert == nil {
+		return ClientCertNone
+	}
+
+	return *vs.ClientCert
+}
+
+// EnableMutualTLS requires client certificates on a virtual service with SSL acceleration. The CA certificates are
+// added to the intermediate certificates of the virtual service, existing assignments are kept.
+func (c *Client) EnableMutualTLS(vs_identifier string, options MutualTLSOptions) (*VirtualServiceResponse, error) {
+	slog.Debug("Enabling mutual tls", "vs_identifier", vs_identifier)
+
+	mode := options.Mode
+	if mode == ClientCertNone {
+		mode = ClientCertRequired
+	}
+	if !mode.Valid() {
+		return nil, fmt.Errorf("invalid client certificate mode %d", mode)
+	}
+	if len(options.CACertificates) == 0 {
+		return nil, fmt.Errorf("no client CA certificates given")
+	}
+
+	installed, err := c.ListClientCACertificate()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range options.CACertificates {
+		if !slices.ContainsFunc(installed, func(certificate ParsedCertificate) bool { return certificate.Name == name }) {
+			return nil, fmt.Errorf("client CA certificate %s is not installed", name)
+		}
+	}
+
+	response, err := c.ShowVirtualService(vs_identifier)
+	if err != nil {
+		return nil, err
+	}
+	if response.VirtualService == nil || response.VirtualServiceParameters == nil || response.VirtualServiceParametersSSLProperties == nil ||
+		response.SSLAcceleration == nil || !*response.SSLAcceleration {
+		return nil, fmt.Errorf("virtual service %s does not have SSL acceleration enabled", vs_identifier)
+	}
+
+	intermediates := response.intermediateCertificateNames()
+	for _, name := range options.CACertificates {
+		if !slices.Contains(intermediates, name) {
+			intermediates = append(intermediates, name)
+		}
+	}
+
+	return c.ModifyVirtualService(strconv.Itoa(int(response.Index)), VirtualServiceParameters{
+		VirtualServiceParametersSSLProperties: &VirtualServiceParametersSSLProperties{
+			ClientCert:        &mode,
+			IntermediateCerts: strings.Join(intermediates, " "),
+			OCSPVerify:        options.OCSPVerify,
+		},
+	})
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientCertMode(t *testing.T) {
+	assert.True(t, ClientCertPassPEMAsXCert.Valid())
+	assert.False(t, ClientCertMode(7).Valid())
+	assert.False(t, ClientCertNone.Required())
+	assert.True(t, ClientCertAddHeaders.Required())
+
+	var properties VirtualServiceParametersSSLProperties
+	require.NoError(t, json.Unmarshal([]byte(`{"ClientCert": 5}`), &properties))
+	assert.Equal(t, ClientCertPassPEMAsSSLClientCert, *properties.ClientCert)
+}
+
+func TestClient_AddClientCACertificate(t *testing.T) {
+	ca := createTestCertificate(t, "Client CA", nil, time.Now().Add(365*24*time.Hour), nil)
+	leaf := createTestCertificate(t, "client", []string{"client.example.com"}, time.Now().Add(24*time.Hour), ca)
+
+	server := createCommandServer(map[string][]string{
+		"addintermediate": {`{"code": 200, "status": "ok"}`},
+	})
+	defer server.Close()
+	client := createClientForUnit(server.Server, "baz")
+
+	_, err := client.AddClientCACertificate("client_ca", []byte(ca.PEM))
+	require.NoError(t, err)
+	assert.Equal(t, "client_ca", server.Requests[0]["cert"])
+	uploaded, err := base64.StdEncoding.DecodeString(server.Requests[0]["data"].(string))
+	require.NoError(t, err)
+	assert.Equal(t, ca.PEM, string(uploaded))
+
+	_, err = client.AddClientCACertificate("client", []byte(leaf.PEM))
+	assert.Error(t, err)
+	_, err = client.AddClientCACertificate("bundle", []byte(ca.PEM+leaf.PEM))
+	assert.Error(t, err)
+	assert.Len(t, server.Commands, 1)
+}
+
+func TestClient_DeleteClientCACertificate(t *testing.T) {
+	server := createCommandServer(map[string][]string{
+		"listvs":          {`{"code": 200, "status": "ok", "VS": [ { "Index": 1, "ClientCert": 1, "IntermediateCerts": "client_ca" }, { "Index": 2, "ClientCert": 0, "IntermediateCerts": "other_ca" } ]}`},
+		"delintermediate": {`{"code": 200, "status": "ok"}`},
+	})
+	defer server.Close()
+	client := createClientForUnit(server.Server, "baz")
+
+	_, err := client.DeleteClientCACertificate("client_ca")
+	var in_use *ClientCAInUseError
+	require.True(t, errors.As(err, &in_use))
+	assert.Equal(t, []int32{1}, in_use.VirtualServices)
+
+	_, err = client.DeleteClientCACertificate("other_ca")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"listvs", "listvs", "delintermediate"}, server.Commands)
+}
+
+func TestClient_EnableMutualTLS(t *testing.T) {
+	ca := createTestCertificate(t, "Client CA", nil, time.Now().Add(365*24*time.Hour), nil)
+	chain := createTestCertificate(t, "example.com", []string{"example.com"}, time.Now().Add(24*time.Hour), ca)
+	show := func(data string) string {
+		encoded, _ := json.Marshal(map[string]any{"code": 200, "status": "ok", "certificate": data})
+		return string(encoded)
+	}
+
+	testCases := []struct {
+		name     string
+		showvs   string
+		options  MutualTLSOptions
+		commands []string
+		wantErr  bool
+	}{
+		{
+			name:     "enable",
+			showvs:   `{"code": 200, "status": "ok", "Index": 3, "SSLAcceleration": true, "IntermediateCerts": "chain"}`,
+			options:  MutualTLSOptions{CACertificates: []string{"client_ca"}, Mode: ClientCertPassPEMAsXCert},
+			commands: []string{"listintermediate", "readintermediate", "readintermediate", "showvs", "modvs"},
+		},
+		{
+			name:     "ssl acceleration disabled",
+			showvs:   `{"code": 200, "status": "ok", "Index": 3}`,
+			options:  MutualTLSOptions{CACertificates: []string{"client_ca"}},
+			commands: []string{"listintermediate", "readintermediate", "readintermediate", "showvs"},
+			wantErr:  true,
+		},
+		{
+			name:     "unknown ca",
+			options:  MutualTLSOptions{CACertificates: []string{"chain"}},
+			commands: []string{"listintermediate", "readintermediate", "readintermediate"},
+			wantErr:  true,
+		},
+		{
+			name:    "no ca",
+			wantErr: true,
+		},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			server := createCommandServer(map[string][]string{
+				"listintermediate": {`{"code": 200, "status": "ok", "cert": [ { "name": "client_ca" }, { "name": "chain" } ]}`},
+				"readintermediate": {show(ca.PEM), show(chain.PEM)},
+				"showvs":           {tt.showvs},
+				"modvs":            {`{"code": 200, "status": "ok"}`},
+			})
+			defer server.Close()
+			client := createClientForUnit(server.Server, "baz")
+
+			_, err := client.EnableMutualTLS("3", tt.options)
+			assert.Equal(t, tt.commands, server.Commands)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			modvs := server.Requests[len(server.Requests)-1]
+			assert.Equal(t, float64(ClientCertPassPEMAsXCert), modvs["ClientCert"])
+			assert.Equal(t, "chain client_ca", modvs["IntermediateCerts"])
+		})
+	}
+}
@@ -1,9 +1,13 @@
 package api
 
 import (
+	"encoding/json"
 	"log/slog"
+	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -87,3 +91,46 @@ func TestLoadMasterRequest_injectAuth(t *testing.T) {
 		})
 	}
 }
+
+type commandServer struct {
+	*httptest.Server
+	Commands []string
+	Requests []map[string]any
+}
+
+// createCommandServer returns a test server which answers each request with
+// the next queued response for its "cmd". The last queued response of a
+// command is repeated once the queue is exhausted, and responses prefixed with
+// "!" are answered with status 400.
+func createCommandServer(responses map[string][]string) *commandServer {
+	cs := &commandServer{}
+	var mu sync.Mutex
+	cs.Server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		body := map[string]any{}
+		_ = json.NewDecoder(req.Body).Decode(&body)
+		command, _ := body["cmd"].(string)
+		cs.Commands = append(cs.Commands, command)
+		cs.Requests = append(cs.Requests, body)
+
+		queue, ok := responses[command]
+		if !ok || len(queue) == 0 {
+			rw.WriteHeader(400)
+			_, _ = rw.Write([]byte(`{"code": 400, "message": "unknown command", "status": "fail"}`))
+			return
+		}
+		response := queue[0]
+		if len(queue) > 1 {
+			responses[command] = queue[1:]
+		}
+		if strings.HasPrefix(response, "!") {
+			rw.WriteHeader(400)
+			response = response[1:]
+		}
+		_, _ = rw.Write([]byte(response))
+	}))
+
+	return cs
+}
@@ -0,0 +1,279 @@
+package api
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+)
+
+// ContentRoute describes a content switching route of a virtual service,
+// for example "path /api/* is served by these real servers".
+// The name is used for the match content rule and as nickname of the sub virtual service.
+type ContentRoute struct {
+	Name            string
+	Pattern         string
+	MatchType       string
+	Header          string
+	CaseIndependent *bool
+	Negate          *bool
+	IncHost         *bool
+	IncQuery        *bool
+	SubVS           VirtualServiceParameters
+	RealServers     []ContentRouteRealServer
+}
+
+type ContentRouteRealServer struct {
+	Address    string
+	Port       int32
+	Parameters RealServerParameters
+}
+
+type ContentRouteResponse struct {
+	*LoadMasterResponse
+	Rule       string
+	SubVSIndex int32
+}
+
+func (r ContentRoute) generalRule() GeneralRule {
+	rule := GeneralRule{
+		Pattern:  &r.Pattern,
+		NoCase:   r.CaseIndependent,
+		Negate:   r.Negate,
+		IncHost:  r.IncHost,
+		IncQuery: r.IncQuery,
+	}
+	if r.MatchType != "" {
+		rule.MatchType = &r.MatchType
+	}
+	if r.Header != "" {
+		rule.Header = &r.Header
+	}
+
+	return rule
+}
+
+func (r ContentRoute) subVirtualServiceParameters() VirtualServiceParameters {
+	parameters := r.SubVS
+	if parameters.VirtualServiceParametersBasicProperties == nil {
+		parameters.VirtualServiceParametersBasicProperties = &VirtualServiceParametersBasicProperties{}
+	}
+	if parameters.NickName == "" {
+		basic := *parameters.VirtualServiceParametersBasicProperties
+		basic.NickName = r.Name
+		parameters.VirtualServiceParametersBasicProperties = &basic
+	}
+
+	return parameters
+}
+
+// AddContentRoute creates a match content rule, a sub virtual service with the given real servers and
+// assigns the rule to the sub virtual service. Content switching is enabled on the parent if it is not yet.
+// If any step fails, all previously created objects are removed again.
+func (c *Client) AddContentRoute(vs_identifier string, route ContentRoute) (*ContentRouteResponse, error) {
+	slog.Debug("Adding content route", "vs_identifier", vs_identifier, "name", route.Name)
+	var undo rollback
+
+	_, err := c.AddRule(RuleTypeMatchContent, route.Name, route.generalRule())
+	if err != nil {
+		return nil, err
+	}
+	undo.push(func() error {
+		_, err := c.DeleteRule(route.Name)
+		return err
+	})
+
+	subvs, err := c.CreateSubVirtualService(vs_identifier, route.subVirtualServiceParameters())
+	if err != nil {
+		return nil, undo.run(err)
+	}
+	subvs_identifier := strconv.Itoa(int(subvs.Index))
+	undo.push(func() error {
+		_, err := c.DeleteSubVirtualService(subvs_identifier)
+		return err
+	})
+
+	for _, rs := range route.RealServers {
+		_, err := c.AddRealServer(subvs_identifier, rs.Address, strconv.Itoa(int(rs.Port)), rs.Parameters)
+		if err != nil {
+			return nil, undo.run(err)
+		}
+	}
+
+	response, err := c.AddSubVirtualServiceRule(vs_identifier, subvs_identifier, route.Name)
+	if err != nil {
+		return nil, undo.run(err)
+	}
+
+	if err := c.enableContentSwitching(vs_identifier); err != nil {
+		return nil, undo.run(err)
+	}
+
+	return &ContentRouteResponse{
+		LoadMasterResponse: response,
+		Rule:               route.Name,
+		SubVSIndex:         subvs.Index,
+	}, nil
+}
+
+// UpdateContentRoute changes the rule and the real servers of an existing route.
+// Real servers are matched by address and port; missing ones are added before obsolete ones are removed.
+// If any step fails, the previous rule and real servers are restored.
+func (c *Client) UpdateContentRoute(vs_identifier string, route ContentRoute) (*ContentRouteResponse, error) {
+	slog.Debug("Updating content route", "vs_identifier", vs_identifier, "name", route.Name)
+	var undo rollback
+
+	subvs, err := c.findContentRoute(vs_identifier, route.Name)
+	if err != nil {
+		return nil, err
+	}
+	subvs_identifier := strconv.Itoa(int(subvs.VSIndex))
+
+	previous_rule, err := c.ShowRule(route.Name)
+	if err != nil {
+		return nil, err
+	}
+	if len(previous_rule.MatchContentRules) != 1 {
+		return nil, fmt.Errorf("rule %s is not a match content rule", route.Name)
+	}
+
+	current, err := c.ShowSubVirtualService(subvs_identifier)
+	if err != nil {
+		return nil, err
+	}
+	var current_rs []RealServer
+	if current.SubVirtualService != nil && current.VirtualService != nil {
+		current_rs = current.Rs
+	}
+
+	response, err := c.ModifyRule(route.Name, route.generalRule())
+	if err != nil {
+		return nil, err
+	}
+	undo.push(func() error {
+		_, err := c.ModifyRule(route.Name, previous_rule.MatchContentRules[0].GeneralRule())
+		return err
+	})
+
+	for _, rs := range route.RealServers {
+		exists := slices.ContainsFunc(current_rs, func(existing RealServer) bool {
+			return existing.Address == rs.Address && existing.Port == rs.Port
+		})
+		if exists {
+			continue
+		}
+
+		added, err := c.AddRealServer(subvs_identifier, rs.Address, strconv.Itoa(int(rs.Port)), rs.Parameters)
+		if err != nil {
+			return nil, undo.run(err)
+		}
+		added_identifier := addedRealServerIdentifier(added, rs.Address, rs.Port)
+		undo.push(func() error {
+			_, err := c.DeleteRealServer(subvs_identifier, added_identifier)
+			return err
+		})
+	}
+
+	for _, existing := range current_rs {
+		wanted := slices.ContainsFunc(route.RealServers, func(rs ContentRouteRealServer) bool {
+			return existing.Address == rs.Address && existing.Port == rs.Port
+		})
+		if wanted {
+			continue
+		}
+
+		_, err := c.DeleteRealServer(subvs_identifier, existing.identifier())
+		if err != nil {
+			return nil, undo.run(err)
+		}
+		undo.push(func() error {
+			_, _, err := c.addRealServerCopy(subvs_identifier, existing)
+			return err
+		})
+	}
+
+	return &ContentRouteResponse{
+		LoadMasterResponse: response.LoadMasterResponse,
+		Rule:               route.Name,
+		SubVSIndex:         subvs.VSIndex,
+	}, nil
+}
+
+// DeleteContentRoute removes the rule assignment, the rule and the sub virtual service of a route.
+// If the rule or the sub virtual service cannot be removed, the rule and its assignment are restored.
+func (c *Client) DeleteContentRoute(vs_identifier string, name string) (*LoadMasterResponse, error) {
+	slog.Debug("Deleting content route", "vs_identifier", vs_identifier, "name", name)
+	var undo rollback
+
+	subvs, err := c.findContentRoute(vs_identifier, name)
+	if err != nil {
+		return nil, err
+	}
+	subvs_identifier := strconv.Itoa(int(subvs.VSIndex))
+
+	previous_rule, err := c.ShowRule(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(previous_rule.MatchContentRules) != 1 {
+		return nil, fmt.Errorf("rule %s is not a match content rule", name)
+	}
+
+	_, err = c.DeleteSubVirtualServiceRule(vs_identifier, subvs_identifier, name)
+	if err != nil {
+		return nil, err
+	}
+	undo.push(func() error {
+		_, err := c.AddSubVirtualServiceRule(vs_identifier, subvs_identifier, name)
+		return err
+	})
+
+	_, err = c.DeleteRule(name)
+	if err != nil {
+		return nil, undo.run(err)
+	}
+	undo.push(func() error {
+		_, err := c.AddRule(RuleTypeMatchContent, name, previous_rule.MatchContentRules[0].GeneralRule())
+		return err
+	})
+
+	response, err := c.DeleteSubVirtualService(subvs_identifier)
+	if err != nil {
+		return nil, undo.run(err)
+	}
+
+	return response, nil
+}
+
+func (c *Client) findContentRoute(vs_identifier string, name string) (*SubVirtualService, error) {
+	response, err := c.ListSubVirtualService(vs_identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, subvs := range response.SubVS {
+		if slices.Contains(subvs.matchRules(), name) {
+			return &subvs, nil
+		}
+	}
+
+	return nil, fmt.Errorf("content route %s not found in virtual service %s", name, vs_identifier)
+}
+
+func (c *Client) enableContentSwitching(vs_identifier string) error {
+	parent, err := c.ShowVirtualService(vs_identifier)
+	if err != nil {
+		return err
+	}
+	if parent.VirtualService != nil && parent.VirtualServiceParameters != nil && parent.VirtualServiceParametersRealServers != nil &&
+		parent.ContentSwitching != nil && *parent.ContentSwitching {
+		return nil
+	}
+
+	enabled := true
+	_, err = c.ModifyVirtualService(vs_identifier, VirtualServiceParameters{
+		VirtualServiceParametersRealServers: &VirtualServiceParametersRealServers{ContentSwitching: &enabled},
+	})
+
+	return err
+}
@@ -0,0 +1,148 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_AddContentRoute(t *testing.T) {
+	route := ContentRoute{
+		Name:        "api",
+		Pattern:     "^/api/",
+		MatchType:   "regex",
+		RealServers: []ContentRouteRealServer{{Address: "10.0.0.100", Port: 80}, {Address: "10.0.0.101", Port: 80}},
+	}
+
+	t.Run("success", func(t *testing.T) {
+		server := createCommandServer(map[string][]string{
+			"addrule":   {`{"code": 200, "status": "ok"}`},
+			"showvs":    {`{"code": 200, "status": "ok", "Index": 1}`, `{"code": 200, "status": "ok", "Index": 2}`},
+			"modvs":     {`{"code": 200, "status": "ok", "Index": 1, "SubVS": [ { "VSIndex": 2 } ]}`},
+			"addrs":     {`{"code": 200, "status": "ok"}`},
+			"addrsrule": {`{"code": 200, "status": "ok"}`},
+		})
+		defer server.Close()
+		client := createClientForUnit(server.Server, "baz")
+
+		rs, err := client.AddContentRoute("1", route)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"addrule", "showvs", "modvs", "showvs", "addrs", "addrs", "addrsrule", "showvs", "modvs"}, server.Commands)
+		assert.Equal(t, "api", server.Requests[0]["name"])
+		assert.Equal(t, "^/api/", server.Requests[0]["pattern"])
+		assert.Equal(t, "api", server.Requests[2]["NickName"])
+		assert.Equal(t, "2", server.Requests[4]["vs"])
+		assert.Equal(t, "1", server.Requests[6]["vs"])
+		assert.Equal(t, "2", server.Requests[6]["rs"])
+		assert.Equal(t, "1", server.Requests[8]["vs"])
+		assert.Equal(t, true, server.Requests[8]["ContentSwitching"])
+		assert.Equal(t, int32(2), rs.SubVSIndex)
+		assert.Equal(t, "api", rs.Rule)
+	})
+
+	t.Run("rollback on failed assignment", func(t *testing.T) {
+		server := createCommandServer(map[string][]string{
+			"addrule":   {`{"code": 200, "status": "ok"}`},
+			"showvs":    {`{"code": 200, "status": "ok", "Index": 1}`, `{"code": 200, "status": "ok", "Index": 2}`},
+			"modvs":     {`{"code": 200, "status": "ok", "Index": 1, "SubVS": [ { "VSIndex": 2 } ]}`},
+			"addrs":     {`{"code": 200, "status": "ok"}`},
+			"addrsrule": {`!{"code": 400, "status": "fail"}`},
+			"delvs":     {`{"code": 200, "status": "ok"}`},
+			"delrule":   {`{"code": 200, "status": "ok"}`},
+		})
+		defer server.Close()
+		client := createClientForUnit(server.Server, "baz")
+
+		_, err := client.AddContentRoute("1", route)
+		require.Error(t, err)
+
+		assert.Equal(t, []string{"delvs", "delrule"}, server.Commands[len(server.Commands)-2:])
+		assert.Equal(t, "2", server.Requests[len(server.Requests)-2]["vs"])
+		assert.Equal(t, "api", server.Requests[len(server.Requests)-1]["name"])
+	})
+}
+
+func TestClient_UpdateContentRoute(t *testing.T) {
+	server := createCommandServer(map[string][]string{
+		"showvs": {
+			`{"code": 200, "status": "ok", "Index": 1, "SubVS": [ { "VSIndex": 2, "MatchRules": ["api"] } ]}`,
+			`{"code": 200, "status": "ok", "Index": 2, "Rs": [ { "RSIndex": 1, "Addr": "10.0.0.100", "Port": 80 }, { "RSIndex": 2, "Addr": "10.0.0.101", "Port": 80 } ]}`,
+		},
+		"showrule": {`{"code": 200, "status": "ok", "MatchContentRule": [ { "name": "api", "pattern": "^/api/", "matchtype": "Regex" } ]}`},
+		"modrule":  {`{"code": 200, "status": "ok"}`},
+		"addrs":    {`{"code": 200, "status": "ok", "Rs": [ { "RSIndex": 3, "Addr": "10.0.0.102", "Port": 80 } ]}`},
+		"delrs":    {`!{"code": 400, "status": "fail"}`},
+	})
+	defer server.Close()
+	client := createClientForUnit(server.Server, "baz")
+
+	_, err := client.UpdateContentRoute("1", ContentRoute{
+		Name:        "api",
+		Pattern:     "^/v2/",
+		RealServers: []ContentRouteRealServer{{Address: "10.0.0.100", Port: 80}, {Address: "10.0.0.102", Port: 80}},
+	})
+	require.Error(t, err)
+
+	assert.Equal(t, []string{"showvs", "showrule", "showvs", "modrule", "addrs", "delrs", "delrs", "modrule"}, server.Commands)
+	assert.Equal(t, "^/v2/", server.Requests[3]["pattern"])
+	assert.Equal(t, "10.0.0.102", server.Requests[4]["rs"])
+	assert.Equal(t, "!2", server.Requests[5]["rs"])
+	assert.Equal(t, "!3", server.Requests[6]["rs"])
+	assert.Equal(t, "^/api/", server.Requests[7]["pattern"])
+	assert.Equal(t, "regex", server.Requests[7]["matchtype"])
+}
+
+func TestClient_UpdateContentRouteRestoresRealServer(t *testing.T) {
+	server := createCommandServer(map[string][]string{
+		"showvs": {
+			`{"code": 200, "status": "ok", "Index": 1, "SubVS": [ { "VSIndex": 2, "MatchRules": ["api"] } ]}`,
+			`{"code": 200, "status": "ok", "Index": 2, "Rs": [
+				{ "RSIndex": 1, "Addr": "10.0.0.100", "Port": 80 },
+				{ "RSIndex": 2, "Addr": "10.0.0.101", "Port": 80, "DnsName": "app.example.com", "Weight": 500, "MatchRules": ["images"] },
+				{ "RSIndex": 3, "Addr": "10.0.0.102", "Port": 80 }
+			]}`,
+		},
+		"showrule":  {`{"code": 200, "status": "ok", "MatchContentRule": [ { "name": "api", "pattern": "^/api/" } ]}`},
+		"modrule":   {`{"code": 200, "status": "ok"}`},
+		"delrs":     {`{"code": 200, "status": "ok"}`, `!{"code": 400, "status": "fail"}`},
+		"addrs":     {`{"code": 200, "status": "ok", "Rs": [ { "RSIndex": 4, "Addr": "10.0.0.101", "Port": 80 } ]}`},
+		"addrsrule": {`{"code": 200, "status": "ok"}`},
+	})
+	defer server.Close()
+	client := createClientForUnit(server.Server, "baz")
+
+	_, err := client.UpdateContentRoute("1", ContentRoute{
+		Name:        "api",
+		Pattern:     "^/api/",
+		RealServers: []ContentRouteRealServer{{Address: "10.0.0.100", Port: 80}},
+	})
+	require.Error(t, err)
+
+	assert.Equal(t, []string{"showvs", "showrule", "showvs", "modrule", "delrs", "delrs", "addrs", "addrsrule", "modrule"}, server.Commands)
+	assert.Equal(t, "10.0.0.101", server.Requests[6]["rs"])
+	assert.Equal(t, "app.example.com", server.Requests[6]["DnsName"])
+	assert.Equal(t, float64(500), server.Requests[6]["Weight"])
+	assert.Equal(t, "!4", server.Requests[7]["rs"])
+	assert.Equal(t, "images", server.Requests[7]["rule"])
+}
+
+func TestClient_DeleteContentRoute(t *testing.T) {
+	server := createCommandServer(map[string][]string{
+		"showvs":    {`{"code": 200, "status": "ok", "Index": 1, "SubVS": [ { "VSIndex": 2, "MatchRules": ["api"] } ]}`},
+		"showrule":  {`{"code": 200, "status": "ok", "MatchContentRule": [ { "name": "api", "pattern": "^/api/" } ]}`},
+		"delrsrule": {`{"code": 200, "status": "ok"}`},
+		"delrule":   {`{"code": 200, "status": "ok"}`},
+		"delvs":     {`{"code": 200, "status": "ok"}`},
+	})
+	defer server.Close()
+	client := createClientForUnit(server.Server, "baz")
+
+	_, err := client.DeleteContentRoute("1", "api")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"showvs", "showrule", "delrsrule", "delrule", "delvs"}, server.Commands)
+
+	_, err = client.DeleteContentRoute("1", "unknown")
+	assert.Error(t, err)
+}
@@ -0,0 +1,328 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// HealthCheck is the typed representation of the real server check of a virtual service.
+// Each check type only carries the fields the LoadMaster evaluates for it.
+type HealthCheck interface {
+	CheckType() string
+	Validate() error
+	Parameters() *VirtualServiceParametersRealServers
+}
+
+type HTTPMethod int32
+
+const (
+	HTTPMethodHead HTTPMethod = 0
+	HTTPMethodGet  HTTPMethod = 1
+	HTTPMethodPost HTTPMethod = 2
+)
+
+type StatusCodeRange struct {
+	From int
+	To   int
+}
+
+type NoHealthCheck struct{}
+
+type ICMPHealthCheck struct{}
+
+type TCPHealthCheck struct {
+	Port int32
+}
+
+type HTTPHealthCheck struct {
+	Port     int32
+	Url      string
+	Host     string
+	Method   HTTPMethod
+	PostData string
+	Pattern  string
+	Codes    []StatusCodeRange
+	// Headers is passed unchanged as CheckHeaders.
+	Headers   string
+	UseHTTP11 bool
+}
+
+type HTTPSHealthCheck struct {
+	HTTPHealthCheck
+}
+
+type DNSHealthCheck struct {
+	Port int32
+	Host string
+}
+
+type LDAPHealthCheck struct {
+	Port     int32
+	Endpoint string
+}
+
+type SMTPHealthCheck struct {
+	Port int32
+}
+
+type RDPHealthCheck struct {
+	Port int32
+}
+
+func (NoHealthCheck) CheckType() string    { return "none" }
+func (ICMPHealthCheck) CheckType() string  { return "icmp" }
+func (TCPHealthCheck) CheckType() string   { return "tcp" }
+func (HTTPHealthCheck) CheckType() string  { return "http" }
+func (HTTPSHealthCheck) CheckType() string { return "https" }
+func (DNSHealthCheck) CheckType() string   { return "dns" }
+func (LDAPHealthCheck) CheckType() string  { return "ldap" }
+func (SMTPHealthCheck) CheckType() string  { return "smtp" }
+func (RDPHealthCheck) CheckType() string   { return "rdp" }
+
+func (NoHealthCheck) Validate() error   { return nil }
+func (ICMPHealthCheck) Validate() error { return nil }
+
+func (h TCPHealthCheck) Validate() error  { return validateCheckPort(h.Port) }
+func (h SMTPHealthCheck) Validate() error { return validateCheckPort(h.Port) }
+func (h RDPHealthCheck) Validate() error  { return validateCheckPort(h.Port) }
+
+func (h DNSHealthCheck) Validate() error {
+	if err := validateCheckPort(h.Port); err != nil {
+		return err
+	}
+	if h.Host == "" {
+		return fmt.Errorf("dns health check requires a host to resolve")
+	}
+
+	return nil
+}
+
+func (h LDAPHealthCheck) Validate() error {
+	if err := validateCheckPort(h.Port); err != nil {
+		return err
+	}
+	if h.Endpoint == "" {
+		return fmt.Errorf("ldap health check requires an endpoint")
+	}
+
+	return nil
+}
+
+func (h HTTPHealthCheck) Validate() error {
+	if err := validateCheckPort(h.Port); err != nil {
+		return err
+	}
+	if h.Url != "" && !strings.HasPrefix(h.Url, "/") {
+		return fmt.Errorf("health check url %q must start with /", h.Url)
+	}
+	switch h.Method {
+	case HTTPMethodHead, HTTPMethodGet:
+		if h.PostData != "" {
+			return fmt.Errorf("health check post data requires the POST method")
+		}
+	case HTTPMethodPost:
+	default:
+		return fmt.Errorf("unknown health check method %d", h.Method)
+	}
+	if h.Pattern != "" && h.Method == HTTPMethodHead {
+		return fmt.Errorf("health check pattern requires the GET or POST method")
+	}
+	for _, code := range h.Codes {
+		if code.From < 100 || code.To > 599 || code.From > code.To {
+			return fmt.Errorf("invalid health check status code range %s", code)
+		}
+	}
+
+	return nil
+}
+
+func (NoHealthCheck) Parameters() *VirtualServiceParametersRealServers {
+	return &VirtualServiceParametersRealServers{CheckType: "none"}
+}
+
+func (ICMPHealthCheck) Parameters() *VirtualServiceParametersRealServers {
+	return &VirtualServiceParametersRealServers{CheckType: "icmp"}
+}
+
+func (h TCPHealthCheck) Parameters() *VirtualServiceParametersRealServers {
+	return &VirtualServiceParametersRealServers{CheckType: h.CheckType(), CheckPort: formatCheckPort(h.Port)}
+}
+
+func (h SMTPHealthCheck) Parameters() *VirtualServiceParametersRealServers {
+	return &VirtualServiceParametersRealServers{CheckType: h.CheckType(), CheckPort: formatCheckPort(h.Port)}
+}
+
+func (h RDPHealthCheck) Parameters() *VirtualServiceParametersRealServers {
+	return &VirtualServiceParametersRealServers{CheckType: h.CheckType(), CheckPort: formatCheckPort(h.Port)}
+}
+
+func (h DNSHealthCheck) Parameters() *VirtualServiceParametersRealServers {
+	return &VirtualServiceParametersRealServers{CheckType: h.CheckType(), CheckPort: formatCheckPort(h.Port), CheckHost: h.Host}
+}
+
+func (h LDAPHealthCheck) Parameters() *VirtualServiceParametersRealServers {
+	return &VirtualServiceParametersRealServers{CheckType: h.CheckType(), CheckPort: formatCheckPort(h.Port), LdapEndpoint32: h.Endpoint}
+}
+
+func (h HTTPHealthCheck) Parameters() *VirtualServiceParametersRealServers {
+	method := int32(h.Method)
+	use_http11 := h.UseHTTP11
+
+	return &VirtualServiceParametersRealServers{
+		CheckType:     h.CheckType(),
+		CheckPort:     formatCheckPort(h.Port),
+		CheckUrl:      h.Url,
+		CheckHost:     h.Host,
+		CheckUseGet:   &method,
+		CheckPostData: h.PostData,
+		CheckPattern:  h.Pattern,
+		CheckCodes:    FormatCheckCodes(h.Codes),
+		CheckHeaders:  h.Headers,
+		CheckUse1_1:   &use_http11,
+	}
+}
+
+func (h HTTPSHealthCheck) Parameters() *VirtualServiceParametersRealServers {
+	parameters := h.HTTPHealthCheck.Parameters()
+	parameters.CheckType = h.CheckType()
+
+	return parameters
+}
+
+// HealthCheckFromParameters converts the flat check fields of a virtual service into the typed health check.
+// Fields which do not apply to the configured check type are ignored.
+func HealthCheckFromParameters(parameters *VirtualServiceParametersRealServers) (HealthCheck, error) {
+	if parameters == nil {
+		return nil, fmt.Errorf("no real server parameters")
+	}
+
+	check_type := strings.ToLower(parameters.CheckType)
+	switch check_type {
+	case "none":
+		return NoHealthCheck{}, nil
+	case "icmp":
+		return ICMPHealthCheck{}, nil
+	case "tcp", "smtp", "rdp", "dns", "ldap", "http", "https":
+	default:
+		return nil, fmt.Errorf("unsupported check type %q", parameters.CheckType)
+	}
+
+	// The port is only parsed for check types which use it, the LoadMaster may leave any value for the others.
+	port, err := parseCheckPort(parameters.CheckPort)
+	if err != nil {
+		return nil, err
+	}
+
+	switch check_type {
+	case "tcp":
+		return TCPHealthCheck{Port: port}, nil
+	case "smtp":
+		return SMTPHealthCheck{Port: port}, nil
+	case "rdp":
+		return RDPHealthCheck{Port: port}, nil
+	case "dns":
+		return DNSHealthCheck{Port: port, Host: parameters.CheckHost}, nil
+	case "ldap":
+		return LDAPHealthCheck{Port: port, Endpoint: parameters.LdapEndpoint32}, nil
+	case "http", "https":
+		codes, err := ParseCheckCodes(parameters.CheckCodes)
+		if err != nil {
+			return nil, err
+		}
+		check := HTTPHealthCheck{
+			Port:     port,
+			Url:      parameters.CheckUrl,
+			Host:     parameters.CheckHost,
+			PostData: parameters.CheckPostData,
+			Pattern:  parameters.CheckPattern,
+			Codes:    codes,
+			Headers:  parameters.CheckHeaders,
+		}
+		if parameters.CheckUseGet != nil {
+			check.Method = HTTPMethod(*parameters.CheckUseGet)
+		}
+		if parameters.CheckUse1_1 != nil {
+			check.UseHTTP11 = *parameters.CheckUse1_1
+		}
+		if check_type == "https" {
+			return HTTPSHealthCheck{check}, nil
+		}
+		return check, nil
+	}
+
+	return nil, fmt.Errorf("unsupported check type %q", parameters.CheckType)
+}
+
+// ParseCheckCodes parses the additional status codes of a http check, for example "200 301-302".
+func ParseCheckCodes(codes string) ([]StatusCodeRange, error) {
+	var ranges []StatusCodeRange
+	for _, field := range strings.FieldsFunc(codes, func(r rune) bool { return r == ' ' || r == ',' }) {
+		from, to, is_range := strings.Cut(field, "-")
+		if !is_range {
+			to = from
+		}
+
+		from_code, err := strconv.Atoi(from)
+		if err != nil {
+			return nil, fmt.Errorf("invalid status code %q", field)
+		}
+		to_code, err := strconv.Atoi(to)
+		if err != nil {
+			return nil, fmt.Errorf("invalid status code %q", field)
+		}
+		ranges = append(ranges, StatusCodeRange{From: from_code, To: to_code})
+	}
+
+	return ranges, nil
+}
+
+func FormatCheckCodes(ranges []StatusCodeRange) string {
+	codes := make([]string, 0, len(ranges))
+	for _, r := range ranges {
+		codes = append(codes, r.String())
+	}
+
+	return strings.Join(codes, " ")
+}
+
+func (r StatusCodeRange) String() string {
+	if r.From == r.To {
+		return strconv.Itoa(r.From)
+	}
+
+	return strconv.Itoa(r.From) + "-" + strconv.Itoa(r.To)
+}
+
+func (r StatusCodeRange) Contains(code int) bool {
+	return code >= r.From && code <= r.To
+}
+
+func validateCheckPort(port int32) error {
+	if port < 0 || port > 65535 {
+		return fmt.Errorf("invalid health check port %d", port)
+	}
+
+	return nil
+}
+
+func formatCheckPort(port int32) string {
+	if port == 0 {
+		return ""
+	}
+
+	return strconv.Itoa(int(port))
+}
+
+func parseCheckPort(port string) (int32, error) {
+	if port == "" {
+		return 0, nil
+	}
+
+	parsed, err := strconv.ParseInt(port, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid health check port %q", port)
+	}
+
+	return int32(parsed), nil
+}
@@ -0,0 +1,99 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCheckCodes(t *testing.T) {
+	testCases := []struct {
+		name    string
+		codes   string
+		want    []StatusCodeRange
+		wantErr bool
+	}{
+		{"empty", "", nil, false},
+		{"single codes", "301 302", []StatusCodeRange{{301, 301}, {302, 302}}, false},
+		{"ranges and commas", "200-204,401", []StatusCodeRange{{200, 204}, {401, 401}}, false},
+		{"invalid code", "20x", nil, true},
+		{"invalid range", "200-", nil, true},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			ranges, err := ParseCheckCodes(tt.codes)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseCheckCodes() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			assert.Equal(t, tt.want, ranges)
+		})
+	}
+}
+
+func TestHealthCheck_Validate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		check   HealthCheck
+		wantErr bool
+	}{
+		{"none", NoHealthCheck{}, false},
+		{"tcp with port", TCPHealthCheck{Port: 8080}, false},
+		{"tcp with invalid port", TCPHealthCheck{Port: 70000}, true},
+		{"http get", HTTPHealthCheck{Url: "/health", Method: HTTPMethodGet, Codes: []StatusCodeRange{{200, 204}}}, false},
+		{"http relative url", HTTPHealthCheck{Url: "health"}, true},
+		{"http post data without post", HTTPHealthCheck{Method: HTTPMethodGet, PostData: "a=b"}, true},
+		{"http pattern with head", HTTPHealthCheck{Method: HTTPMethodHead, Pattern: "OK"}, true},
+		{"https invalid code", HTTPSHealthCheck{HTTPHealthCheck{Codes: []StatusCodeRange{{600, 600}}}}, true},
+		{"ldap without endpoint", LDAPHealthCheck{}, true},
+		{"dns without host", DNSHealthCheck{}, true},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.check.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("%T.Validate() error = %v, wantErr %v", tt.check, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHealthCheckFromParameters(t *testing.T) {
+	check := HTTPSHealthCheck{HTTPHealthCheck{
+		Port:      8443,
+		Url:       "/status",
+		Method:    HTTPMethodPost,
+		PostData:  "ping",
+		Codes:     []StatusCodeRange{{200, 200}, {301, 302}},
+		UseHTTP11: true,
+	}}
+
+	parameters := check.Parameters()
+	assert.Equal(t, "https", parameters.CheckType)
+	assert.Equal(t, "8443", parameters.CheckPort)
+	assert.Equal(t, "200 301-302", parameters.CheckCodes)
+	assert.Equal(t, int32(2), *parameters.CheckUseGet)
+
+	parsed, err := HealthCheckFromParameters(parameters)
+	require.NoError(t, err)
+	assert.Equal(t, check, parsed)
+
+	parsed, err = HealthCheckFromParameters(&VirtualServiceParametersRealServers{CheckType: "tcp", CheckPort: "22", CheckUrl: "/ignored"})
+	require.NoError(t, err)
+	assert.Equal(t, TCPHealthCheck{Port: 22}, parsed)
+
+	parsed, err = HealthCheckFromParameters(&VirtualServiceParametersRealServers{CheckType: "icmp", CheckPort: ""})
+	require.NoError(t, err)
+	assert.Equal(t, ICMPHealthCheck{}, parsed)
+
+	parsed, err = HealthCheckFromParameters(&VirtualServiceParametersRealServers{CheckType: "none", CheckPort: "n/a"})
+	require.NoError(t, err)
+	assert.Equal(t, NoHealthCheck{}, parsed)
+
+	_, err = HealthCheckFromParameters(&VirtualServiceParametersRealServers{CheckType: "tcp", CheckPort: "n/a"})
+	assert.ErrorContains(t, err, "invalid health check port")
+
+	_, err = HealthCheckFromParameters(&VirtualServiceParametersRealServers{CheckType: "unknown"})
+	assert.Error(t, err)
+}
@@ -1,6 +1,9 @@
 package api
 
-import "log/slog"
+import (
+	"log/slog"
+	"strconv"
+)
 
 type ListRealServerResponse struct {
 	*LoadMasterResponse
@@ -22,6 +25,7 @@ type RealServer struct {
 	Critical   *bool    `json:"Critical,omitempty"`
 	Nrules     int32    `json:"Nrules,omitempty"`
 	MatchRules []string `json:"MatchRules,omitempty"`
+	Status     string   `json:"Status,omitempty"`
 }
 
 type RealServerParameters struct {
@@ -38,6 +42,65 @@ type RealServerParameters struct {
 	Nrules    int32  `json:"Nrules,omitempty"`
 }
 
+// identifier returns the index based identifier of the real server, usable as rs_identifier.
+func (rs RealServer) identifier() string {
+	return "!" + strconv.Itoa(int(rs.RsIndex))
+}
+
+// matches reports whether the real server is addressed by the identifier, either "!<index>" or "<address>:<port>".
+func (rs RealServer) matches(rs_identifier string) bool {
+	if rs_identifier == rs.identifier() {
+		return true
+	}
+
+	return rs_identifier == rs.Address+":"+strconv.Itoa(int(rs.Port))
+}
+
+// addedRealServerIdentifier returns the index based identifier of the real server in the response of AddRealServer,
+// or "<address>:<port>" if the response does not contain it.
+func addedRealServerIdentifier(response *ListRealServerResponse, address string, port int32) string {
+	for _, rs := range response.Rs {
+		if rs.Address == address && rs.Port == port {
+			return rs.identifier()
+		}
+	}
+
+	return address + ":" + strconv.Itoa(int(port))
+}
+
+// addRealServerCopy adds a real server with the parameters and rules of rs to the virtual service and returns the
+// identifier of the new real server. If a rule cannot be assigned, the new real server is removed again.
+func (c *Client) addRealServerCopy(vs_identifier string, rs RealServer) (*ListRealServerResponse, string, error) {
+	response, err := c.AddRealServer(vs_identifier, rs.Address, strconv.Itoa(int(rs.Port)), RealServerParameters{
+		DnsName:   rs.DnsName,
+		Forward:   rs.Forward,
+		Weight:    rs.Weight,
+		Limit:     rs.Limit,
+		RateLimit: rs.RateLimit,
+		Follow:    rs.Follow,
+		Enable:    rs.Enable,
+		Critical:  rs.Critical,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	identifier := addedRealServerIdentifier(response, rs.Address, rs.Port)
+
+	for _, rule := range rs.MatchRules {
+		_, err := c.AddRealServerRule(vs_identifier, identifier, rule)
+		if err != nil {
+			var undo rollback
+			undo.push(func() error {
+				_, err := c.DeleteRealServer(vs_identifier, identifier)
+				return err
+			})
+			return nil, "", undo.run(err)
+		}
+	}
+
+	return response, identifier, nil
+}
+
 func (c *Client) AddRealServer(vs_identifier string, address string, port string, params RealServerParameters) (*ListRealServerResponse, error) {
 	slog.Debug("Adding real server", "vs_identifier", vs_identifier, "address", address, "port", port)
 	payload := struct {
@@ -137,3 +200,117 @@ func (c *Client) DeleteRealServer(vs_identifier string, rs_identifier string) (*
 
 	return response, nil
 }
+
+type RealServerUsage struct {
+	VirtualService *VirtualService
+	RealServer     RealServer
+}
+
+type FindRealServerResponse struct {
+	*LoadMasterResponse
+	Usages []RealServerUsage
+}
+
+// ListRealServer returns all real servers of the given (sub) virtual service.
+func (c *Client) ListRealServer(vs_identifier string) (*ListRealServerResponse, error) {
+	slog.Debug("Listing real servers", "vs_identifier", vs_identifier)
+
+	response, err := c.ShowVirtualService(vs_identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ListRealServerResponse{LoadMasterResponse: response.LoadMasterResponse}
+	if response.VirtualService != nil {
+		result.Rs = response.Rs
+	}
+
+	return result, nil
+}
+
+// FindRealServer returns every virtual service and sub virtual service using the backend address.
+// If port is 0, real servers on any port of the address are returned.
+func (c *Client) FindRealServer(address string, port int32) (*FindRealServerResponse, error) {
+	slog.Debug("Finding real server", "address", address, "port", port)
+
+	response, err := c.ListVirtualService()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &FindRealServerResponse{LoadMasterResponse: response.LoadMasterResponse}
+	for i := range response.VS {
+		vs := &response.VS[i]
+		for _, rs := range vs.Rs {
+			if rs.Address != address || (port != 0 && rs.Port != port) {
+				continue
+			}
+			result.Usages = append(result.Usages, RealServerUsage{VirtualService: vs, RealServer: rs})
+		}
+	}
+
+	return result, nil
+}
+
+type ToggleRealServerResponse struct {
+	*LoadMasterResponse
+	// Global is true if the appliance's global real server control was used.
+	Global  bool
+	Touched []RealServerUsage
+}
+
+// EnableRealServerGlobally enables the backend in every virtual service referencing it.
+// If port is 0, real servers on any port of the address are enabled.
+func (c *Client) EnableRealServerGlobally(address string, port int32) (*ToggleRealServerResponse, error) {
+	return c.toggleRealServerGlobally(address, port, true)
+}
+
+// DisableRealServerGlobally disables the backend in every virtual service referencing it.
+// If port is 0, real servers on any port of the address are disabled.
+func (c *Client) DisableRealServerGlobally(address string, port int32) (*ToggleRealServerResponse, error) {
+	return c.toggleRealServerGlobally(address, port, false)
+}
+
+func (c *Client) toggleRealServerGlobally(address string, port int32, enable bool) (*ToggleRealServerResponse, error) {
+	slog.Debug("Toggle real server globally", "address", address, "port", port, "enable", enable)
+
+	usages, err := c.FindRealServer(address, port)
+	if err != nil {
+		return nil, err
+	}
+
+	// The global control addresses all real servers of an address, so it can only be used without port.
+	if port == 0 {
+		command := "disablers"
+		if enable {
+			command = "enablers"
+		}
+		payload := struct {
+			*LoadMasterRequest
+			RS string `json:"rs"`
+		}{
+			LoadMasterRequest: &LoadMasterRequest{
+				Command: command,
+			},
+			RS: address,
+		}
+
+		response, err := sendRequest(c, payload, LoadMasterResponse{})
+		if err == nil {
+			return &ToggleRealServerResponse{LoadMasterResponse: response, Global: true, Touched: usages.Usages}, nil
+		}
+		slog.Debug("Global real server control failed, falling back to modifying each real server", "error", err)
+	}
+
+	result := &ToggleRealServerResponse{LoadMasterResponse: usages.LoadMasterResponse}
+	for _, usage := range usages.Usages {
+		response, err := c.ModifyRealServer(strconv.Itoa(int(usage.VirtualService.Index)), usage.RealServer.identifier(), RealServerParameters{Enable: &enable})
+		if err != nil {
+			return result, err
+		}
+		result.LoadMasterResponse = response.LoadMasterResponse
+		result.Touched = append(result.Touched, usage)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,175 @@
+package api
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+type RealServerState string
+
+const (
+	RealServerStateUnknown  RealServerState = "unknown"
+	RealServerStateUp       RealServerState = "up"
+	RealServerStateDown     RealServerState = "down"
+	RealServerStateDisabled RealServerState = "disabled"
+	RealServerStateDraining RealServerState = "draining"
+	// RealServerStateRemoved is reported once for a real server which is no longer part of its virtual service.
+	RealServerStateRemoved RealServerState = "removed"
+)
+
+type RealServerHealthEvent struct {
+	VSIndex    int32
+	RealServer RealServer
+	// Previous is empty for the first observation of a real server.
+	Previous RealServerState
+	Current  RealServerState
+	Time     time.Time
+	// Err is set if polling the LoadMaster failed; no real server is attached in that case.
+	Err error
+}
+
+// State derives the health state from the reported status and the enable flag of the real server.
+func (rs RealServer) State() RealServerState {
+	status := strings.ToLower(rs.Status)
+	switch {
+	case strings.Contains(status, "drain"):
+		return RealServerStateDraining
+	case rs.Enable != nil && !*rs.Enable, status == "disabled":
+		return RealServerStateDisabled
+	case status == "up":
+		return RealServerStateUp
+	case status == "down":
+		return RealServerStateDown
+	}
+
+	return RealServerStateUnknown
+}
+
+// WatchRealServerHealth polls the real servers at the given interval and emits an event whenever the
+// state of a real server changes. The initial state of every real server is emitted on the first poll.
+// A real server missing from a poll is reported with RealServerStateRemoved and is treated as new if it reappears.
+// Without vs_identifiers the real servers of all virtual services are watched.
+// The returned channel is closed once ctx is cancelled. The interval must be positive.
+func (c *Client) WatchRealServerHealth(ctx context.Context, interval time.Duration, vs_identifiers ...string) (<-chan RealServerHealthEvent, error) {
+	slog.Debug("Watching real server health", "interval", interval, "vs_identifiers", vs_identifiers)
+	if interval <= 0 {
+		return nil, fmt.Errorf("interval must be positive, got %s", interval)
+	}
+
+	events := make(chan RealServerHealthEvent)
+
+	go func() {
+		defer close(events)
+
+		type key struct{ vs, rs int32 }
+		type observation struct {
+			rs    RealServer
+			state RealServerState
+		}
+		states := map[key]observation{}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			usages, err := c.pollRealServers(vs_identifiers)
+			now := time.Now()
+			if err != nil {
+				select {
+				case events <- RealServerHealthEvent{Time: now, Err: err}:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			polled := map[key]bool{}
+			for _, usage := range usages {
+				k := key{usage.RealServer.VSIndex, usage.RealServer.RsIndex}
+				polled[k] = true
+				current := usage.RealServer.State()
+				previous := states[k]
+				if previous.state == current {
+					continue
+				}
+				states[k] = observation{usage.RealServer, current}
+
+				select {
+				case events <- RealServerHealthEvent{VSIndex: k.vs, RealServer: usage.RealServer, Previous: previous.state, Current: current, Time: now}:
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			if err == nil {
+				removed := slices.SortedFunc(maps.Keys(states), func(a, b key) int {
+					return cmp.Or(cmp.Compare(a.vs, b.vs), cmp.Compare(a.rs, b.rs))
+				})
+				for _, k := range removed {
+					if polled[k] {
+						continue
+					}
+					previous := states[k]
+					delete(states, k)
+
+					select {
+					case events <- RealServerHealthEvent{VSIndex: k.vs, RealServer: previous.rs, Previous: previous.state, Current: RealServerStateRemoved, Time: now}:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+func (c *Client) pollRealServers(vs_identifiers []string) ([]RealServerUsage, error) {
+	var usages []RealServerUsage
+
+	if len(vs_identifiers) == 0 {
+		response, err := c.ListVirtualService()
+		if err != nil {
+			return nil, err
+		}
+		for i := range response.VS {
+			for _, rs := range response.VS[i].Rs {
+				if rs.VSIndex == 0 {
+					rs.VSIndex = response.VS[i].Index
+				}
+				usages = append(usages, RealServerUsage{VirtualService: &response.VS[i], RealServer: rs})
+			}
+		}
+
+		return usages, nil
+	}
+
+	for _, vs_identifier := range vs_identifiers {
+		response, err := c.ShowVirtualService(vs_identifier)
+		if err != nil {
+			return nil, err
+		}
+		if response.VirtualService == nil {
+			continue
+		}
+		for _, rs := range response.Rs {
+			if rs.VSIndex == 0 {
+				rs.VSIndex = response.Index
+			}
+			usages = append(usages, RealServerUsage{VirtualService: response.VirtualService, RealServer: rs})
+		}
+	}
+
+	return usages, nil
+}
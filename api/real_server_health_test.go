@@ -0,0 +1,96 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRealServer_State(t *testing.T) {
+	testCases := []struct {
+		name string
+		rs   RealServer
+		want RealServerState
+	}{
+		{"up", RealServer{Status: "Up"}, RealServerStateUp},
+		{"down", RealServer{Status: "Down"}, RealServerStateDown},
+		{"disabled status", RealServer{Status: "Disabled"}, RealServerStateDisabled},
+		{"disabled flag", RealServer{Status: "Up", Enable: convert2Ptr(false)}, RealServerStateDisabled},
+		{"draining", RealServer{Status: "Draining", Enable: convert2Ptr(false)}, RealServerStateDraining},
+		{"unknown", RealServer{}, RealServerStateUnknown},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.rs.State())
+		})
+	}
+}
+
+func TestClient_WatchRealServerHealth(t *testing.T) {
+	server := createCommandServer(map[string][]string{
+		"showvs": {
+			`{"code": 200, "status": "ok", "Index": 1, "Rs": [ { "RSIndex": 1, "Status": "Up" }, { "RSIndex": 2, "Status": "Up" } ]}`,
+			`{"code": 200, "status": "ok", "Index": 1, "Rs": [ { "RSIndex": 1, "Status": "Up" }, { "RSIndex": 2, "Status": "Down" } ]}`,
+		},
+	})
+	defer server.Close()
+	client := createClientForUnit(server.Server, "baz")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err := client.WatchRealServerHealth(ctx, 0, "1")
+	require.Error(t, err)
+
+	events, err := client.WatchRealServerHealth(ctx, time.Millisecond, "1")
+	require.NoError(t, err)
+
+	var received []RealServerHealthEvent
+	for event := range events {
+		require.NoError(t, event.Err)
+		received = append(received, event)
+		if len(received) == 3 {
+			cancel()
+		}
+	}
+
+	require.Len(t, received, 3)
+	assert.Equal(t, RealServerState(""), received[0].Previous)
+	assert.Equal(t, RealServerStateUp, received[0].Current)
+	assert.Equal(t, int32(1), received[0].VSIndex)
+	assert.Equal(t, int32(2), received[2].RealServer.RsIndex)
+	assert.Equal(t, RealServerStateUp, received[2].Previous)
+	assert.Equal(t, RealServerStateDown, received[2].Current)
+}
+
+func TestClient_WatchRealServerHealthRemoved(t *testing.T) {
+	server := createCommandServer(map[string][]string{
+		"showvs": {
+			`{"code": 200, "status": "ok", "Index": 1, "Rs": [ { "RSIndex": 1, "Status": "Up" }, { "RSIndex": 2, "Status": "Down" } ]}`,
+			`{"code": 200, "status": "ok", "Index": 1, "Rs": [ { "RSIndex": 1, "Status": "Up" } ]}`,
+		},
+	})
+	defer server.Close()
+	client := createClientForUnit(server.Server, "baz")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	events, err := client.WatchRealServerHealth(ctx, time.Millisecond, "1")
+	require.NoError(t, err)
+
+	var received []RealServerHealthEvent
+	for event := range events {
+		require.NoError(t, event.Err)
+		received = append(received, event)
+		if len(received) == 3 {
+			cancel()
+		}
+	}
+
+	require.Len(t, received, 3)
+	assert.Equal(t, int32(2), received[2].RealServer.RsIndex)
+	assert.Equal(t, RealServerStateDown, received[2].Previous)
+	assert.Equal(t, RealServerStateRemoved, received[2].Current)
+}
@@ -0,0 +1,171 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+)
+
+type DesiredRealServer struct {
+	Address    string
+	Port       int32
+	Parameters RealServerParameters
+}
+
+type ReconcileRealServerOptions struct {
+	// Drain disables a real server and waits DrainPeriod before it is removed.
+	Drain       bool
+	DrainPeriod time.Duration
+}
+
+type RealServerChange struct {
+	Address    string
+	Port       int32
+	Current    *RealServer
+	Parameters RealServerParameters
+}
+
+type ReconcileRealServerReport struct {
+	Added     []RealServerChange
+	Modified  []RealServerChange
+	Removed   []RealServerChange
+	Unchanged []RealServerChange
+}
+
+func realServerKey(address string, port int32) string {
+	return address + ":" + strconv.Itoa(int(port))
+}
+
+// differs reports whether any parameter set in desired deviates from the real server.
+// Unset parameters are not compared.
+func (rs RealServer) differs(desired RealServerParameters) bool {
+	if desired.Weight != 0 && desired.Weight != rs.Weight {
+		return true
+	}
+	if desired.Limit != 0 && desired.Limit != rs.Limit {
+		return true
+	}
+	if desired.RateLimit != 0 && desired.RateLimit != rs.RateLimit {
+		return true
+	}
+	if desired.Follow != 0 && desired.Follow != rs.Follow {
+		return true
+	}
+	if desired.Forward != "" && desired.Forward != rs.Forward {
+		return true
+	}
+	if desired.DnsName != "" && desired.DnsName != rs.DnsName {
+		return true
+	}
+	if desired.Enable != nil && (rs.Enable == nil || *desired.Enable != *rs.Enable) {
+		return true
+	}
+	if desired.Critical != nil && (rs.Critical == nil || *desired.Critical != *rs.Critical) {
+		return true
+	}
+
+	return false
+}
+
+// PlanRealServerReconciliation computes which real servers have to be added, modified and removed
+// so that current matches desired. Real servers are matched by address and port.
+func PlanRealServerReconciliation(current []RealServer, desired []DesiredRealServer) (*ReconcileRealServerReport, error) {
+	existing := map[string]RealServer{}
+	for _, rs := range current {
+		existing[realServerKey(rs.Address, rs.Port)] = rs
+	}
+
+	report := &ReconcileRealServerReport{}
+	wanted := map[string]bool{}
+	for _, rs := range desired {
+		key := realServerKey(rs.Address, rs.Port)
+		if wanted[key] {
+			return nil, fmt.Errorf("real server %s is listed more than once", key)
+		}
+		wanted[key] = true
+
+		change := RealServerChange{Address: rs.Address, Port: rs.Port, Parameters: rs.Parameters}
+		found, ok := existing[key]
+		if !ok {
+			report.Added = append(report.Added, change)
+			continue
+		}
+
+		change.Current = &found
+		if found.differs(rs.Parameters) {
+			report.Modified = append(report.Modified, change)
+		} else {
+			report.Unchanged = append(report.Unchanged, change)
+		}
+	}
+
+	for _, rs := range current {
+		if !wanted[realServerKey(rs.Address, rs.Port)] {
+			report.Removed = append(report.Removed, RealServerChange{Address: rs.Address, Port: rs.Port, Current: &rs})
+		}
+	}
+
+	return report, nil
+}
+
+// ReconcileRealServer makes the real servers of a virtual service match desired.
+// New real servers are added and changed ones modified before obsolete ones are removed.
+// On error the returned report contains the changes applied so far.
+func (c *Client) ReconcileRealServer(ctx context.Context, vs_identifier string, desired []DesiredRealServer, options ReconcileRealServerOptions) (*ReconcileRealServerReport, error) {
+	slog.Debug("Reconciling real servers", "vs_identifier", vs_identifier)
+
+	response, err := c.ListRealServer(vs_identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	plan, err := PlanRealServerReconciliation(response.Rs, desired)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ReconcileRealServerReport{Unchanged: plan.Unchanged}
+	for _, change := range plan.Added {
+		_, err := c.AddRealServer(vs_identifier, change.Address, strconv.Itoa(int(change.Port)), change.Parameters)
+		if err != nil {
+			return report, err
+		}
+		report.Added = append(report.Added, change)
+	}
+
+	for _, change := range plan.Modified {
+		_, err := c.ModifyRealServer(vs_identifier, change.Current.identifier(), change.Parameters)
+		if err != nil {
+			return report, err
+		}
+		report.Modified = append(report.Modified, change)
+	}
+
+	if options.Drain && len(plan.Removed) > 0 {
+		disabled := false
+		for _, change := range plan.Removed {
+			_, err := c.ModifyRealServer(vs_identifier, change.Current.identifier(), RealServerParameters{Enable: &disabled})
+			if err != nil {
+				return report, err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return report, ctx.Err()
+		case <-time.After(options.DrainPeriod):
+		}
+	}
+
+	for _, change := range plan.Removed {
+		_, err := c.DeleteRealServer(vs_identifier, change.Current.identifier())
+		if err != nil {
+			return report, err
+		}
+		report.Removed = append(report.Removed, change)
+	}
+
+	return report, nil
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanRealServerReconciliation(t *testing.T) {
+	current := []RealServer{
+		{RsIndex: 1, Address: "10.0.0.100", Port: 80, Weight: 1000},
+		{RsIndex: 2, Address: "10.0.0.101", Port: 80, Weight: 1000},
+		{RsIndex: 3, Address: "10.0.0.102", Port: 80, Weight: 1000},
+	}
+	desired := []DesiredRealServer{
+		{Address: "10.0.0.100", Port: 80, Parameters: RealServerParameters{Weight: 1000}},
+		{Address: "10.0.0.101", Port: 80, Parameters: RealServerParameters{Weight: 500}},
+		{Address: "10.0.0.103", Port: 80},
+	}
+
+	plan, err := PlanRealServerReconciliation(current, desired)
+	require.NoError(t, err)
+
+	require.Len(t, plan.Added, 1)
+	assert.Equal(t, "10.0.0.103", plan.Added[0].Address)
+	require.Len(t, plan.Modified, 1)
+	assert.Equal(t, int32(2), plan.Modified[0].Current.RsIndex)
+	require.Len(t, plan.Removed, 1)
+	assert.Equal(t, int32(3), plan.Removed[0].Current.RsIndex)
+	require.Len(t, plan.Unchanged, 1)
+	assert.Equal(t, "10.0.0.100", plan.Unchanged[0].Address)
+
+	_, err = PlanRealServerReconciliation(current, []DesiredRealServer{{Address: "10.0.0.100", Port: 80}, {Address: "10.0.0.100", Port: 80}})
+	assert.Error(t, err)
+}
+
+func TestClient_ReconcileRealServer(t *testing.T) {
+	server := createCommandServer(map[string][]string{
+		"showvs": {`{"code": 200, "status": "ok", "Index": 1, "Rs": [ { "RSIndex": 1, "Addr": "10.0.0.100", "Port": 80, "Weight": 1000 }, { "RSIndex": 2, "Addr": "10.0.0.101", "Port": 80, "Weight": 1000 } ]}`},
+		"addrs":  {`{"code": 200, "status": "ok"}`},
+		"modrs":  {`{"code": 200, "status": "ok"}`},
+		"delrs":  {`{"code": 200, "status": "ok"}`},
+	})
+	defer server.Close()
+	client := createClientForUnit(server.Server, "baz")
+
+	report, err := client.ReconcileRealServer(context.Background(), "1", []DesiredRealServer{
+		{Address: "10.0.0.100", Port: 80, Parameters: RealServerParameters{Weight: 200}},
+		{Address: "10.0.0.102", Port: 8080},
+	}, ReconcileRealServerOptions{Drain: true, DrainPeriod: time.Millisecond})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"showvs", "addrs", "modrs", "modrs", "delrs"}, server.Commands)
+	assert.Equal(t, "10.0.0.102", server.Requests[1]["rs"])
+	assert.Equal(t, "8080", server.Requests[1]["rsport"])
+	assert.Equal(t, "!1", server.Requests[2]["rs"])
+	assert.Equal(t, float64(200), server.Requests[2]["Weight"])
+	assert.Equal(t, "!2", server.Requests[3]["rs"])
+	assert.Equal(t, false, server.Requests[3]["Enable"])
+	assert.Equal(t, "!2", server.Requests[4]["rs"])
+
+	assert.Len(t, report.Added, 1)
+	assert.Len(t, report.Modified, 1)
+	assert.Len(t, report.Removed, 1)
+}
+
+func TestClient_ReconcileRealServerCancelledDrain(t *testing.T) {
+	server := createCommandServer(map[string][]string{
+		"showvs": {`{"code": 200, "status": "ok", "Index": 1, "Rs": [ { "RSIndex": 1, "Addr": "10.0.0.100", "Port": 80 } ]}`},
+		"modrs":  {`{"code": 200, "status": "ok"}`},
+	})
+	defer server.Close()
+	client := createClientForUnit(server.Server, "baz")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	report, err := client.ReconcileRealServer(ctx, "1", nil, ReconcileRealServerOptions{Drain: true, DrainPeriod: time.Hour})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, report.Removed)
+	assert.Equal(t, []string{"showvs", "modrs"}, server.Commands)
+}
@@ -6,6 +6,9 @@ import (
 	"net/http/httptest"
 	"reflect"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestClient_ShowRealServer(t *testing.T) {
@@ -45,3 +48,99 @@ func TestClient_ShowRealServer(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_ListRealServer(t *testing.T) {
+	server := createCommandServer(map[string][]string{
+		"showvs": {`{"code": 200, "status": "ok", "Index": 1, "Rs": [ { "RSIndex": 1, "Addr": "10.0.0.100", "Port": 80 }, { "RSIndex": 2, "Addr": "10.0.0.101", "Port": 80 } ]}`},
+	})
+	defer server.Close()
+	client := createClientForUnit(server.Server, "baz")
+
+	rs, err := client.ListRealServer("1")
+	require.NoError(t, err)
+
+	assert.Equal(t, []RealServer{{RsIndex: 1, Address: "10.0.0.100", Port: 80}, {RsIndex: 2, Address: "10.0.0.101", Port: 80}}, rs.Rs)
+}
+
+func TestClient_FindRealServer(t *testing.T) {
+	server := createCommandServer(map[string][]string{
+		"listvs": {`{"code": 200, "status": "ok", "VS": [
+			{ "Index": 1, "VSAddress": "10.0.0.4", "VSPort": "80", "Rs": [ { "RSIndex": 1, "Addr": "10.1.2.3", "Port": 8080 }, { "RSIndex": 2, "Addr": "10.1.2.4", "Port": 8080 } ] },
+			{ "Index": 2, "VSAddress": "10.0.0.4", "VSPort": "443", "Rs": [ { "RSIndex": 3, "Addr": "10.1.2.3", "Port": 8443 } ] },
+			{ "Index": 3, "VSAddress": "", "VSPort": "", "MasterVS": 1, "Rs": [ { "RSIndex": 4, "Addr": "10.1.2.3", "Port": 8080 } ] }
+		]}`},
+	})
+	defer server.Close()
+	client := createClientForUnit(server.Server, "baz")
+
+	testCases := []struct {
+		name    string
+		address string
+		port    int32
+		want    []int32
+	}{
+		{"address and port", "10.1.2.3", 8080, []int32{1, 3}},
+		{"any port", "10.1.2.3", 0, []int32{1, 2, 3}},
+		{"unused", "10.9.9.9", 0, nil},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			rs, err := client.FindRealServer(tt.address, tt.port)
+			require.NoError(t, err)
+
+			var indices []int32
+			for _, usage := range rs.Usages {
+				assert.Equal(t, tt.address, usage.RealServer.Address)
+				indices = append(indices, usage.VirtualService.Index)
+			}
+			assert.Equal(t, tt.want, indices)
+		})
+	}
+}
+
+func TestClient_DisableRealServerGlobally(t *testing.T) {
+	listvs := `{"code": 200, "status": "ok", "VS": [
+		{ "Index": 1, "Rs": [ { "RSIndex": 1, "Addr": "10.1.2.3", "Port": 8080 } ] },
+		{ "Index": 2, "Rs": [ { "RSIndex": 3, "Addr": "10.1.2.3", "Port": 8443 } ] }
+	]}`
+
+	testCases := []struct {
+		name       string
+		port       int32
+		disablers  string
+		wantGlobal bool
+		want       []string
+	}{
+		{"global control", 0, `{"code": 200, "status": "ok"}`, true, []string{"listvs", "disablers"}},
+		{"fallback on failed global control", 0, `!{"code": 400, "status": "fail"}`, false, []string{"listvs", "disablers", "modrs", "modrs"}},
+		{"per virtual service with port", 8080, `{"code": 200, "status": "ok"}`, false, []string{"listvs", "modrs"}},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			server := createCommandServer(map[string][]string{
+				"listvs":    {listvs},
+				"disablers": {tt.disablers},
+				"modrs":     {`{"code": 200, "status": "ok"}`},
+			})
+			defer server.Close()
+			client := createClientForUnit(server.Server, "baz")
+
+			rs, err := client.DisableRealServerGlobally("10.1.2.3", tt.port)
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, server.Commands)
+			assert.Equal(t, tt.wantGlobal, rs.Global)
+			for i, command := range server.Commands {
+				if command == "modrs" {
+					assert.Equal(t, false, server.Requests[i]["Enable"])
+				}
+			}
+			if tt.port == 0 {
+				assert.Len(t, rs.Touched, 2)
+			} else {
+				require.Len(t, rs.Touched, 1)
+				assert.Equal(t, int32(1), rs.Touched[0].VirtualService.Index)
+			}
+		})
+	}
+}
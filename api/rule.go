@@ -1,6 +1,10 @@
 package api
 
-import "log/slog"
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+)
 
 type RuleResponse struct {
 	*LoadMasterResponse
@@ -125,6 +129,10 @@ func (c *Client) ShowRule(name string) (*RuleResponse, error) {
 
 func (c *Client) AddRule(rule_type string, name string, params GeneralRule) (*RuleResponse, error) {
 	slog.Debug("Adding rule", "name", name, "type", rule_type)
+	if err := ValidateRulePattern(rule_type, params); err != nil {
+		return nil, err
+	}
+
 	payload := struct {
 		*LoadMasterRequest
 		*GeneralRule
@@ -150,6 +158,10 @@ func (c *Client) AddRule(rule_type string, name string, params GeneralRule) (*Ru
 
 func (c *Client) ModifyRule(name string, params GeneralRule) (*RuleResponse, error) {
 	slog.Debug("Modifying rule", "name", name)
+	if err := ValidateRulePattern("", params); err != nil {
+		return nil, err
+	}
+
 	payload := struct {
 		*LoadMasterRequest
 		*GeneralRule
@@ -190,3 +202,184 @@ func (c *Client) DeleteRule(name string) (*LoadMasterResponse, error) {
 
 	return response, nil
 }
+
+const (
+	RuleTypeMatchContent  = "0"
+	RuleTypeAddHeader     = "1"
+	RuleTypeDeleteHeader  = "2"
+	RuleTypeReplaceHeader = "3"
+	RuleTypeModifyURL     = "4"
+	RuleTypeReplaceBody   = "5"
+)
+
+// Rule is implemented by every concrete rule type returned in a RuleResponse.
+// A rule read from the LoadMaster can be written back unchanged with AddTypedRule or ModifyTypedRule.
+type Rule interface {
+	RuleName() string
+	RuleType() string
+	GeneralRule() GeneralRule
+}
+
+func (r MatchContentRule) RuleName() string  { return r.Name }
+func (r AddHeaderRule) RuleName() string     { return r.Name }
+func (r DeleteHeaderRule) RuleName() string  { return r.Name }
+func (r ReplaceHeaderRule) RuleName() string { return r.Name }
+func (r ModifyURLRule) RuleName() string     { return r.Name }
+func (r ReplaceBodyRule) RuleName() string   { return r.Name }
+
+func (MatchContentRule) RuleType() string  { return RuleTypeMatchContent }
+func (AddHeaderRule) RuleType() string     { return RuleTypeAddHeader }
+func (DeleteHeaderRule) RuleType() string  { return RuleTypeDeleteHeader }
+func (ReplaceHeaderRule) RuleType() string { return RuleTypeReplaceHeader }
+func (ModifyURLRule) RuleType() string     { return RuleTypeModifyURL }
+func (ReplaceBodyRule) RuleType() string   { return RuleTypeReplaceBody }
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+
+	return &value
+}
+
+func (r MatchContentRule) GeneralRule() GeneralRule {
+	rule := GeneralRule{
+		Pattern:      optionalString(r.Pattern),
+		NoCase:       r.CaseIndependent,
+		IncHost:      r.IncHost,
+		Negate:       r.Negate,
+		IncQuery:     r.IncQuery,
+		Header:       r.Header,
+		SetOnMatch:   r.SetOnMatch,
+		OnlyOnFlag:   r.OnlyOnFlag,
+		OnlyOnNoFlag: r.OnlyOnNoFlag,
+		MustFail:     r.MustFail,
+	}
+	if r.MatchType != "" {
+		match_type := strings.ToLower(r.MatchType)
+		rule.MatchType = &match_type
+	}
+
+	return rule
+}
+
+func (r AddHeaderRule) GeneralRule() GeneralRule {
+	return GeneralRule{
+		Header:       r.Header,
+		Replacement:  optionalString(r.Replacement),
+		OnlyOnFlag:   r.OnlyOnFlag,
+		OnlyOnNoFlag: r.OnlyOnNoFlag,
+	}
+}
+
+func (r DeleteHeaderRule) GeneralRule() GeneralRule {
+	return GeneralRule{
+		Pattern:      optionalString(r.Pattern),
+		OnlyOnFlag:   r.OnlyOnFlag,
+		OnlyOnNoFlag: r.OnlyOnNoFlag,
+	}
+}
+
+func (r ReplaceHeaderRule) GeneralRule() GeneralRule {
+	return GeneralRule{
+		Header:       r.Header,
+		Replacement:  optionalString(r.Replacement),
+		Pattern:      optionalString(r.Pattern),
+		OnlyOnFlag:   r.OnlyOnFlag,
+		OnlyOnNoFlag: r.OnlyOnNoFlag,
+	}
+}
+
+func (r ModifyURLRule) GeneralRule() GeneralRule {
+	return GeneralRule{
+		Replacement:  optionalString(r.Replacement),
+		Pattern:      optionalString(r.Pattern),
+		OnlyOnFlag:   r.OnlyOnFlag,
+		OnlyOnNoFlag: r.OnlyOnNoFlag,
+	}
+}
+
+func (r ReplaceBodyRule) GeneralRule() GeneralRule {
+	return GeneralRule{
+		Replacement:     optionalString(r.Replacement),
+		Pattern:         optionalString(r.Pattern),
+		CaseIndependent: r.CaseIndependent,
+		OnlyOnFlag:      r.OnlyOnFlag,
+		OnlyOnNoFlag:    r.OnlyOnNoFlag,
+	}
+}
+
+// Rules returns all rules of the response regardless of their type.
+func (r *RuleResponse) Rules() []Rule {
+	var rules []Rule
+	for _, rule := range r.MatchContentRules {
+		rules = append(rules, rule)
+	}
+	for _, rule := range r.AddHeaderRules {
+		rules = append(rules, rule)
+	}
+	for _, rule := range r.DeleteHeaderRules {
+		rules = append(rules, rule)
+	}
+	for _, rule := range r.ReplaceHeaderRules {
+		rules = append(rules, rule)
+	}
+	for _, rule := range r.ModifyURLRules {
+		rules = append(rules, rule)
+	}
+	for _, rule := range r.ReplaceBodyRules {
+		rules = append(rules, rule)
+	}
+
+	return rules
+}
+
+func (r *RuleResponse) rule(name string) (Rule, error) {
+	for _, rule := range r.Rules() {
+		if rule.RuleName() == name {
+			return rule, nil
+		}
+	}
+
+	return nil, fmt.Errorf("rule %s not found in response", name)
+}
+
+func (c *Client) ShowTypedRule(name string) (Rule, error) {
+	slog.Debug("Showing typed rule", "name", name)
+	response, err := c.ShowRule(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return response.rule(name)
+}
+
+func (c *Client) AddTypedRule(rule Rule) (Rule, error) {
+	slog.Debug("Adding typed rule", "name", rule.RuleName(), "type", rule.RuleType())
+	response, err := c.AddRule(rule.RuleType(), rule.RuleName(), rule.GeneralRule())
+	if err != nil {
+		return nil, err
+	}
+
+	return response.rule(rule.RuleName())
+}
+
+// ModifyTypedRule changes an existing rule. The type of a rule cannot be changed.
+func (c *Client) ModifyTypedRule(rule Rule) (Rule, error) {
+	slog.Debug("Modifying typed rule", "name", rule.RuleName(), "type", rule.RuleType())
+
+	current, err := c.ShowTypedRule(rule.RuleName())
+	if err != nil {
+		return nil, err
+	}
+	if current.RuleType() != rule.RuleType() {
+		return nil, fmt.Errorf("rule %s has type %s, not %s", rule.RuleName(), current.RuleType(), rule.RuleType())
+	}
+
+	response, err := c.ModifyRule(rule.RuleName(), rule.GeneralRule())
+	if err != nil {
+		return nil, err
+	}
+
+	return response.rule(rule.RuleName())
+}
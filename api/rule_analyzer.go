@@ -0,0 +1,164 @@
+package api
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+)
+
+type RuleProblemKind string
+
+const (
+	RuleProblemUnreachable           RuleProblemKind = "unreachable"
+	RuleProblemFlagNeverSet          RuleProblemKind = "flag_never_set"
+	RuleProblemFlagNeverRead         RuleProblemKind = "flag_never_read"
+	RuleProblemConflictingConditions RuleProblemKind = "conflicting_conditions"
+	RuleProblemInvalidFlag           RuleProblemKind = "invalid_flag"
+	RuleProblemUnassigned            RuleProblemKind = "unassigned"
+)
+
+type RuleProblem struct {
+	Kind RuleProblemKind
+	Rule string
+	// VSIndex is 0 for problems which are not specific to a virtual service.
+	VSIndex int32
+	Flag    int32
+	Message string
+}
+
+type AnalyzeRuleResponse struct {
+	*LoadMasterResponse
+	Problems []RuleProblem
+}
+
+// ruleEvaluationOrder lists the phases in the order the LoadMaster processes them for a request.
+var ruleEvaluationOrder = []RulePhase{RulePhasePreProcess, RulePhaseRequest, RulePhaseRealServer, RulePhaseResponse, RulePhaseMatchBody}
+
+// assignedRules returns the rules of all phases of the virtual service in evaluation order.
+func (vs *VirtualService) assignedRules() []string {
+	var rules []string
+	for _, phase := range ruleEvaluationOrder {
+		phase_rules, _ := vs.rulePrecedence(phase)
+		if phase == RulePhaseRealServer && len(phase_rules) == 0 {
+			phase_rules = vs.assignedRealServerRules()
+		}
+		for _, rule := range phase_rules {
+			if !slices.Contains(rules, rule) {
+				rules = append(rules, rule)
+			}
+		}
+	}
+
+	return rules
+}
+
+func flagValue(flag *int32) int32 {
+	if flag == nil {
+		return 0
+	}
+
+	return *flag
+}
+
+// AnalyzeRules reports rules which can never fire, flags which are read but never set or set but never read within
+// a virtual service, rules with conflicting flag conditions and rules which are not assigned to any virtual service.
+func AnalyzeRules(rules []Rule, services []VirtualService) []RuleProblem {
+	var problems []RuleProblem
+
+	by_name := map[string]GeneralRule{}
+	var names []string
+	for _, rule := range rules {
+		by_name[rule.RuleName()] = rule.GeneralRule()
+		names = append(names, rule.RuleName())
+	}
+
+	for _, name := range names {
+		rule := by_name[name]
+		for _, flag := range []int32{flagValue(rule.SetOnMatch), flagValue(rule.OnlyOnFlag), flagValue(rule.OnlyOnNoFlag)} {
+			if flag < 0 || flag > 9 {
+				problems = append(problems, RuleProblem{Kind: RuleProblemInvalidFlag, Rule: name, Flag: flag, Message: fmt.Sprintf("rule %s uses flag %d, only flags 1-9 exist", name, flag)})
+			}
+		}
+
+		only, only_not := flagValue(rule.OnlyOnFlag), flagValue(rule.OnlyOnNoFlag)
+		if only != 0 && only == only_not {
+			problems = append(problems, RuleProblem{Kind: RuleProblemConflictingConditions, Rule: name, Flag: only, Message: fmt.Sprintf("rule %s requires flag %d to be set and not set", name, only)})
+		}
+	}
+
+	// Flags are evaluated per request, so they are tracked per virtual service.
+	type vsFlag struct{ vs, flag int32 }
+	assigned := map[string]bool{}
+	set_flags := map[vsFlag][]string{}
+	read_flags := map[vsFlag][]string{}
+	for i := range services {
+		vs := &services[i]
+		available := map[int32]bool{}
+		for _, name := range vs.assignedRules() {
+			assigned[name] = true
+			rule, ok := by_name[name]
+			if !ok {
+				continue
+			}
+
+			if flag := flagValue(rule.OnlyOnFlag); flag != 0 {
+				read_flags[vsFlag{vs.Index, flag}] = append(read_flags[vsFlag{vs.Index, flag}], name)
+				if !available[flag] {
+					problems = append(problems, RuleProblem{Kind: RuleProblemUnreachable, Rule: name, VSIndex: vs.Index, Flag: flag, Message: fmt.Sprintf("rule %s in virtual service %d requires flag %d which no preceding rule sets", name, vs.Index, flag)})
+				}
+			}
+			if flag := flagValue(rule.OnlyOnNoFlag); flag != 0 {
+				read_flags[vsFlag{vs.Index, flag}] = append(read_flags[vsFlag{vs.Index, flag}], name)
+			}
+			if flag := flagValue(rule.SetOnMatch); flag != 0 {
+				available[flag] = true
+				set_flags[vsFlag{vs.Index, flag}] = append(set_flags[vsFlag{vs.Index, flag}], name)
+			}
+		}
+	}
+
+	for _, name := range names {
+		if !assigned[name] {
+			problems = append(problems, RuleProblem{Kind: RuleProblemUnassigned, Rule: name, Message: fmt.Sprintf("rule %s is not assigned to any virtual service", name)})
+		}
+	}
+
+	for i := range services {
+		index := services[i].Index
+		for flag := int32(1); flag <= 9; flag++ {
+			key := vsFlag{index, flag}
+			for _, name := range read_flags[key] {
+				if len(set_flags[key]) == 0 {
+					problems = append(problems, RuleProblem{Kind: RuleProblemFlagNeverSet, Rule: name, VSIndex: index, Flag: flag, Message: fmt.Sprintf("rule %s in virtual service %d reads flag %d which is never set", name, index, flag)})
+				}
+			}
+			if len(read_flags[key]) == 0 {
+				for _, name := range set_flags[key] {
+					problems = append(problems, RuleProblem{Kind: RuleProblemFlagNeverRead, Rule: name, VSIndex: index, Flag: flag, Message: fmt.Sprintf("rule %s in virtual service %d sets flag %d which is never read", name, index, flag)})
+				}
+			}
+		}
+	}
+
+	return problems
+}
+
+// AnalyzeRuleFlags loads all rules and virtual services and runs AnalyzeRules on them.
+func (c *Client) AnalyzeRuleFlags() (*AnalyzeRuleResponse, error) {
+	slog.Debug("Analyzing rule flags")
+
+	rules, err := c.ListRule()
+	if err != nil {
+		return nil, err
+	}
+
+	services, err := c.ListVirtualService()
+	if err != nil {
+		return nil, err
+	}
+
+	return &AnalyzeRuleResponse{
+		LoadMasterResponse: services.LoadMasterResponse,
+		Problems:           AnalyzeRules(rules.Rules(), services.VS),
+	}, nil
+}
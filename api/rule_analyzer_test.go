@@ -0,0 +1,88 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzeRules(t *testing.T) {
+	rules := []Rule{
+		MatchContentRule{Name: "set1", Pattern: "^/api", SetOnMatch: convert2Ptr(int32(1))},
+		AddHeaderRule{Name: "on1", Header: convert2Ptr("X-Api"), OnlyOnFlag: convert2Ptr(int32(1))},
+		ModifyURLRule{Name: "early", Pattern: "a", OnlyOnFlag: convert2Ptr(int32(1))},
+		MatchContentRule{Name: "set2", Pattern: "^/web", SetOnMatch: convert2Ptr(int32(2))},
+		DeleteHeaderRule{Name: "on3", Pattern: "X-Remove", OnlyOnFlag: convert2Ptr(int32(3))},
+		ReplaceBodyRule{Name: "conflict", Pattern: "a", OnlyOnFlag: convert2Ptr(int32(1)), OnlyOnNoFlag: convert2Ptr(int32(1))},
+		MatchContentRule{Name: "orphan", Pattern: "^/"},
+	}
+	services := []VirtualService{
+		{
+			Index:      1,
+			MatchRules: []string{"early", "set1"},
+			VirtualServiceParameters: &VirtualServiceParameters{
+				VirtualServiceParametersAdvancedProperties: &VirtualServiceParametersAdvancedProperties{RequestRules: []string{"on1", "set2", "on3"}},
+			},
+		},
+		{Index: 2, Rs: []RealServer{{RsIndex: 1, MatchRules: []string{"conflict"}}}},
+	}
+
+	problems := AnalyzeRules(rules, services)
+
+	find := func(kind RuleProblemKind, rule string) *RuleProblem {
+		for _, problem := range problems {
+			if problem.Kind == kind && problem.Rule == rule {
+				return &problem
+			}
+		}
+		return nil
+	}
+
+	unreachable := find(RuleProblemUnreachable, "early")
+	require.NotNil(t, unreachable)
+	assert.Equal(t, int32(1), unreachable.VSIndex)
+	assert.Nil(t, find(RuleProblemUnreachable, "on1"))
+	assert.NotNil(t, find(RuleProblemUnreachable, "conflict"))
+	assert.NotNil(t, find(RuleProblemFlagNeverSet, "on3"))
+	assert.NotNil(t, find(RuleProblemFlagNeverRead, "set2"))
+	assert.Nil(t, find(RuleProblemFlagNeverRead, "set1"))
+	assert.NotNil(t, find(RuleProblemConflictingConditions, "conflict"))
+	assert.NotNil(t, find(RuleProblemUnassigned, "orphan"))
+	assert.Nil(t, find(RuleProblemUnassigned, "on1"))
+}
+
+func TestClient_AnalyzeRuleFlags(t *testing.T) {
+	server := createCommandServer(map[string][]string{
+		"showrule": {`{"code": 200, "status": "ok", "MatchContentRule": [ { "name": "set1", "pattern": "a", "SetFlagOnMatch": 1 } ], "AddHeaderRule": [ { "name": "on1", "header": "X", "onlyonflag": 1 } ]}`},
+		"listvs":   {`{"code": 200, "status": "ok", "VS": [ { "Index": 1, "RuleList": "set1 on1" } ]}`},
+	})
+	defer server.Close()
+	client := createClientForUnit(server.Server, "baz")
+
+	response, err := client.AnalyzeRuleFlags()
+	require.NoError(t, err)
+	assert.Empty(t, response.Problems)
+}
+
+func TestAnalyzeRulesFlagsPerVirtualService(t *testing.T) {
+	rules := []Rule{
+		MatchContentRule{Name: "set1", Pattern: "^/api", SetOnMatch: convert2Ptr(int32(1))},
+		AddHeaderRule{Name: "on1", Header: convert2Ptr("X-Api"), Replacement: "yes", OnlyOnFlag: convert2Ptr(int32(1))},
+	}
+	services := []VirtualService{
+		{Index: 1, MatchRules: []string{"set1"}, Rs: []RealServer{{RsIndex: 1, MatchRules: []string{"on1"}}}},
+		{Index: 2, Rs: []RealServer{{RsIndex: 2, MatchRules: []string{"on1"}}}},
+	}
+
+	var never_set []RuleProblem
+	for _, problem := range AnalyzeRules(rules, services) {
+		if problem.Kind == RuleProblemFlagNeverSet {
+			never_set = append(never_set, problem)
+		}
+	}
+
+	require.Len(t, never_set, 1)
+	assert.Equal(t, "on1", never_set[0].Rule)
+	assert.Equal(t, int32(2), never_set[0].VSIndex)
+}
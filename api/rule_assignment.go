@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log/slog"
 	"slices"
+	"strconv"
 )
 
 func (c *Client) AddRealServerRule(vs_identifier string, rs_index string, rule_name string) (*LoadMasterResponse, error) {
@@ -51,6 +52,9 @@ func (c *Client) ShowRealServerRule(vs_identifier string, rs_index string, rule_
 		return nil, err
 	}
 
+	if len(response.Rs) == 0 {
+		return nil, fmt.Errorf("real server %s not found", rs_index)
+	}
 	rules := response.Rs[len(response.Rs)-1].MatchRules
 
 	if !slices.Contains(rules, rule_name) {
@@ -130,7 +134,14 @@ func (c *Client) ShowSubVirtualServiceRule(vs_identifier string, subvs_identifie
 		return nil, err
 	}
 
-	rules := response.SubVS[len(response.SubVS)-1].MatchRules
+	var subvs []SubVirtualService
+	if response.SubVirtualService != nil {
+		subvs = response.VirtualService.subVirtualServices()
+	}
+	if len(subvs) == 0 {
+		return nil, fmt.Errorf("sub virtual service %s not found", subvs_identifier)
+	}
+	rules := subvs[len(subvs)-1].matchRules()
 
 	if !slices.Contains(rules, rule_name) {
 		return nil, fmt.Errorf("rule %s not found in sub virtual service %s", rule_name, subvs_identifier)
@@ -451,3 +462,150 @@ func (c *Client) DeleteVirtualServiceResponseBodyRule(vs_identifier string, rule
 
 	return response, nil
 }
+
+type AssignedRuleResponse struct {
+	*LoadMasterResponse
+	Rules   []string
+	Added   []string
+	Removed []string
+}
+
+// ListRealServerRules returns all rules assigned to the real server in the order the LoadMaster reports them.
+// The real server is identified by "!<index>" or "<address>:<port>".
+func (c *Client) ListRealServerRules(vs_identifier string, rs_identifier string) (*AssignedRuleResponse, error) {
+	slog.Debug("Listing real server rules", "vs_identifier", vs_identifier, "rs_identifier", rs_identifier)
+
+	response, err := c.ShowVirtualService(vs_identifier)
+	if err != nil {
+		return nil, err
+	}
+	if response.VirtualService == nil {
+		return nil, fmt.Errorf("virtual service %s not found", vs_identifier)
+	}
+
+	for _, rs := range response.Rs {
+		if rs.matches(rs_identifier) {
+			return &AssignedRuleResponse{LoadMasterResponse: response.LoadMasterResponse, Rules: rs.MatchRules}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("real server %s not found in virtual service %s", rs_identifier, vs_identifier)
+}
+
+// ListSubVirtualServiceRules returns all rules assigned to the sub virtual service in the order the LoadMaster reports them.
+func (c *Client) ListSubVirtualServiceRules(vs_identifier string, subvs_identifier string) (*AssignedRuleResponse, error) {
+	slog.Debug("Listing sub virtual service rules", "vs_identifier", vs_identifier, "subvs_identifier", subvs_identifier)
+
+	response, err := c.ShowVirtualService(vs_identifier)
+	if err != nil {
+		return nil, err
+	}
+	if response.VirtualService == nil {
+		return nil, fmt.Errorf("virtual service %s not found", vs_identifier)
+	}
+
+	for _, subvs := range response.subVirtualServices() {
+		if strconv.Itoa(int(subvs.VSIndex)) == subvs_identifier {
+			return &AssignedRuleResponse{LoadMasterResponse: response.LoadMasterResponse, Rules: subvs.matchRules()}, nil
+		}
+	}
+
+	return nil, fmt.Errorf("sub virtual service %s not found in virtual service %s", subvs_identifier, vs_identifier)
+}
+
+// ReconcileRealServerRules assigns the missing rules and removes the rules which are not desired.
+// If any step fails, the previous assignments are restored.
+func (c *Client) ReconcileRealServerRules(vs_identifier string, rs_identifier string, rules []string) (*AssignedRuleResponse, error) {
+	slog.Debug("Reconciling real server rules", "vs_identifier", vs_identifier, "rs_identifier", rs_identifier, "rules", rules)
+
+	current, err := c.ListRealServerRules(vs_identifier, rs_identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := c.reconcileRules(current.Rules, rules,
+		func(rule string) error {
+			_, err := c.AddRealServerRule(vs_identifier, rs_identifier, rule)
+			return err
+		},
+		func(rule string) error {
+			_, err := c.DeleteRealServerRule(vs_identifier, rs_identifier, rule)
+			return err
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := c.ListRealServerRules(vs_identifier, rs_identifier)
+	if err != nil {
+		return nil, err
+	}
+	result.LoadMasterResponse = updated.LoadMasterResponse
+	result.Rules = updated.Rules
+
+	return result, nil
+}
+
+// ReconcileSubVirtualServiceRules assigns the missing rules and removes the rules which are not desired.
+// If any step fails, the previous assignments are restored.
+func (c *Client) ReconcileSubVirtualServiceRules(vs_identifier string, subvs_identifier string, rules []string) (*AssignedRuleResponse, error) {
+	slog.Debug("Reconciling sub virtual service rules", "vs_identifier", vs_identifier, "subvs_identifier", subvs_identifier, "rules", rules)
+
+	current, err := c.ListSubVirtualServiceRules(vs_identifier, subvs_identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := c.reconcileRules(current.Rules, rules,
+		func(rule string) error {
+			_, err := c.AddSubVirtualServiceRule(vs_identifier, subvs_identifier, rule)
+			return err
+		},
+		func(rule string) error {
+			_, err := c.DeleteSubVirtualServiceRule(vs_identifier, subvs_identifier, rule)
+			return err
+		},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	updated, err := c.ListSubVirtualServiceRules(vs_identifier, subvs_identifier)
+	if err != nil {
+		return nil, err
+	}
+	result.LoadMasterResponse = updated.LoadMasterResponse
+	result.Rules = updated.Rules
+
+	return result, nil
+}
+
+func (c *Client) reconcileRules(current []string, desired []string, add func(string) error, remove func(string) error) (*AssignedRuleResponse, error) {
+	var undo rollback
+	result := &AssignedRuleResponse{}
+
+	for _, rule := range desired {
+		if slices.Contains(current, rule) || slices.Contains(result.Added, rule) {
+			continue
+		}
+		if err := add(rule); err != nil {
+			return nil, undo.run(err)
+		}
+		undo.push(func() error { return remove(rule) })
+		result.Added = append(result.Added, rule)
+	}
+
+	for _, rule := range current {
+		if slices.Contains(desired, rule) {
+			continue
+		}
+		if err := remove(rule); err != nil {
+			return nil, undo.run(err)
+		}
+		undo.push(func() error { return add(rule) })
+		result.Removed = append(result.Removed, rule)
+	}
+
+	return result, nil
+}
@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestClient_AddRealServerRuleAssignment(t *testing.T) {
@@ -171,3 +172,84 @@ func TestIntegration_RealServerRuleAssignment(t *testing.T) {
 		}
 	})
 }
+
+func TestClient_ListRealServerRules(t *testing.T) {
+	server := createCommandServer(map[string][]string{
+		"showvs": {`{"code": 200, "status": "ok", "Index": 1, "Rs": [ { "RSIndex": 3, "Addr": "10.0.0.1", "Port": 80, "MatchRules": ["a", "b"] }, { "RSIndex": 4, "Addr": "10.0.0.2", "Port": 80 } ], "SubVS": [ { "VSIndex": 2, "MatchRules": ["c"] } ]}`},
+	})
+	defer server.Close()
+	client := createClientForUnit(server.Server, "baz")
+
+	response, err := client.ListRealServerRules("1", "!3")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, response.Rules)
+
+	response, err = client.ListRealServerRules("1", "10.0.0.2:80")
+	require.NoError(t, err)
+	assert.Empty(t, response.Rules)
+
+	_, err = client.ListRealServerRules("1", "!9")
+	assert.Error(t, err)
+
+	response, err = client.ListSubVirtualServiceRules("1", "2")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c"}, response.Rules)
+}
+
+func TestClient_ShowSubVirtualServiceRule(t *testing.T) {
+	server := createCommandServer(map[string][]string{
+		"showrs": {
+			`{"code": 200, "status": "ok", "Index": 1, "SubVS": [ { "VSIndex": 2, "MatchRules": ["a"] } ]}`,
+			`{"code": 200, "status": "ok", "Index": 1, "SubVS": [ { "VSIndex": 2, "MatchRules": ["a"] } ]}`,
+			`{"code": 200, "status": "ok"}`,
+		},
+	})
+	defer server.Close()
+	client := createClientForUnit(server.Server, "baz")
+
+	_, err := client.ShowSubVirtualServiceRule("1", "2", "a")
+	require.NoError(t, err)
+
+	_, err = client.ShowSubVirtualServiceRule("1", "2", "b")
+	assert.ErrorContains(t, err, "rule b not found")
+
+	_, err = client.ShowSubVirtualServiceRule("1", "2", "a")
+	assert.ErrorContains(t, err, "sub virtual service 2 not found")
+}
+
+func TestClient_ReconcileRealServerRules(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		server := createCommandServer(map[string][]string{
+			"showvs": {
+				`{"code": 200, "status": "ok", "Index": 1, "Rs": [ { "RSIndex": 3, "MatchRules": ["a", "b"] } ]}`,
+				`{"code": 200, "status": "ok", "Index": 1, "Rs": [ { "RSIndex": 3, "MatchRules": ["b", "c"] } ]}`,
+			},
+			"addrsrule": {`{"code": 200, "status": "ok"}`},
+			"delrsrule": {`{"code": 200, "status": "ok"}`},
+		})
+		defer server.Close()
+		client := createClientForUnit(server.Server, "baz")
+
+		response, err := client.ReconcileRealServerRules("1", "!3", []string{"b", "c"})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"c"}, response.Added)
+		assert.Equal(t, []string{"a"}, response.Removed)
+		assert.Equal(t, []string{"b", "c"}, response.Rules)
+		assert.Equal(t, []string{"showvs", "addrsrule", "delrsrule", "showvs"}, server.Commands)
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		server := createCommandServer(map[string][]string{
+			"showvs":    {`{"code": 200, "status": "ok", "Index": 1, "SubVS": [ { "VSIndex": 2, "MatchRules": ["a"] } ]}`},
+			"addrsrule": {`{"code": 200, "status": "ok"}`},
+			"delrsrule": {`!{"code": 400, "status": "fail", "message": "failed"}`, `{"code": 200, "status": "ok"}`},
+		})
+		defer server.Close()
+		client := createClientForUnit(server.Server, "baz")
+
+		_, err := client.ReconcileSubVirtualServiceRules("1", "2", []string{"c"})
+		assert.Error(t, err)
+		assert.Equal(t, []string{"showvs", "addrsrule", "delrsrule", "delrsrule"}, server.Commands)
+		assert.Equal(t, "c", server.Requests[3]["rule"])
+	})
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"fmt"
+	"regexp/syntax"
+	"strconv"
+	"strings"
+)
+
+// unsupportedPatternConstructs lists regular expression constructs the LoadMaster does not evaluate,
+// together with the reason reported to the caller.
+var unsupportedPatternConstructs = []struct {
+	prefix  string
+	message string
+}{
+	{"(?<=", "lookbehind is not supported"},
+	{"(?<!", "negative lookbehind is not supported"},
+	{"(?=", "lookahead is not supported"},
+	{"(?!", "negative lookahead is not supported"},
+	{"(?>", "atomic groups are not supported"},
+	{"(?P<", "named groups are not supported"},
+	{"(?<", "named groups are not supported"},
+	{"(?'", "named groups are not supported"},
+	{"(?#", "comments are not supported"},
+	{"(?", "inline flags are not supported, use the nocase option instead"},
+}
+
+// ValidateRulePattern checks the pattern and replacement of a rule before it is sent to the LoadMaster.
+// Patterns of the prefix and postfix match types are literals, every other pattern is a regular expression
+// which must not use lookaround, atomic or named groups, inline flags, possessive quantifiers or back-references.
+// Back-references (\1 to \9) in the replacement must refer to an existing capture group.
+// The rule type may be empty if it is unknown, as is the case when modifying a rule.
+func ValidateRulePattern(rule_type string, params GeneralRule) error {
+	if params.MatchType != nil {
+		switch strings.ToLower(*params.MatchType) {
+		case "regex", "":
+		case "prefix", "postfix":
+			return nil
+		default:
+			return fmt.Errorf("unknown match type %q, expected regex, prefix or postfix", *params.MatchType)
+		}
+	}
+	if params.Pattern == nil || rule_type == RuleTypeAddHeader {
+		return nil
+	}
+
+	captures, err := parseRulePattern(*params.Pattern)
+	if err != nil {
+		return err
+	}
+
+	if params.Replacement == nil || rule_type == RuleTypeMatchContent {
+		return nil
+	}
+	for _, match := range replacementBackReference.FindAllStringSubmatch(*params.Replacement, -1) {
+		group, _ := strconv.Atoi(match[1])
+		if group > captures {
+			return fmt.Errorf("replacement %q references group %d, but pattern %q has %d capture groups", *params.Replacement, group, *params.Pattern, captures)
+		}
+	}
+
+	return nil
+}
+
+// parseRulePattern validates the regular expression and returns the number of capture groups.
+func parseRulePattern(pattern string) (int, error) {
+	in_class := false
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case pattern[i] == '\\' && i+1 < len(pattern):
+			if !in_class && pattern[i+1] >= '1' && pattern[i+1] <= '9' {
+				return 0, fmt.Errorf("invalid pattern %q: back-references are not supported", pattern)
+			}
+			i++
+		case in_class:
+			in_class = pattern[i] != ']'
+		case pattern[i] == '[':
+			in_class = true
+			// A closing bracket directly after the opening one is part of the class.
+			if strings.HasPrefix(pattern[i+1:], "]") || strings.HasPrefix(pattern[i+1:], "^]") {
+				i += strings.Index(pattern[i:], "]")
+			}
+		case pattern[i] == '(' && !strings.HasPrefix(pattern[i:], "(?:"):
+			for _, construct := range unsupportedPatternConstructs {
+				if strings.HasPrefix(pattern[i:], construct.prefix) {
+					return 0, fmt.Errorf("invalid pattern %q: %s", pattern, construct.message)
+				}
+			}
+		case pattern[i] == '+' && i > 0 && strings.ContainsRune("*+?}", rune(pattern[i-1])) && (i < 2 || pattern[i-2] != '\\'):
+			return 0, fmt.Errorf("invalid pattern %q: possessive quantifiers are not supported", pattern)
+		}
+	}
+
+	parsed, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return 0, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+
+	return parsed.MaxCap(), nil
+}
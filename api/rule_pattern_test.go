@@ -0,0 +1,53 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateRulePattern(t *testing.T) {
+	testCases := []struct {
+		name      string
+		rule_type string
+		params    GeneralRule
+		wantErr   bool
+	}{
+		{"regex", RuleTypeMatchContent, GeneralRule{Pattern: convert2Ptr(`^/api/(v[0-9]+)/`)}, false},
+		{"non capturing group", RuleTypeMatchContent, GeneralRule{Pattern: convert2Ptr(`^/(?:a|b)$`)}, false},
+		{"bracket in class", RuleTypeMatchContent, GeneralRule{Pattern: convert2Ptr(`[](?=]`)}, false},
+		{"escaped plus", RuleTypeMatchContent, GeneralRule{Pattern: convert2Ptr(`a\++`)}, false},
+		{"syntax error", RuleTypeMatchContent, GeneralRule{Pattern: convert2Ptr(`^/api(`)}, true},
+		{"lookahead", RuleTypeMatchContent, GeneralRule{Pattern: convert2Ptr(`^/api(?=/)`)}, true},
+		{"lookbehind", RuleTypeMatchContent, GeneralRule{Pattern: convert2Ptr(`(?<!x)y`)}, true},
+		{"named group", RuleTypeModifyURL, GeneralRule{Pattern: convert2Ptr(`(?P<v>.*)`)}, true},
+		{"inline flag", RuleTypeMatchContent, GeneralRule{Pattern: convert2Ptr(`(?i)^/api`)}, true},
+		{"possessive", RuleTypeMatchContent, GeneralRule{Pattern: convert2Ptr(`a*+b`)}, true},
+		{"back-reference in pattern", RuleTypeMatchContent, GeneralRule{Pattern: convert2Ptr(`(a)\1`)}, true},
+		{"prefix is literal", RuleTypeMatchContent, GeneralRule{Pattern: convert2Ptr(`/api(`), MatchType: convert2Ptr("prefix")}, false},
+		{"unknown match type", RuleTypeMatchContent, GeneralRule{Pattern: convert2Ptr(`/api`), MatchType: convert2Ptr("glob")}, true},
+		{"replacement", RuleTypeModifyURL, GeneralRule{Pattern: convert2Ptr(`^/v1/(.*)$`), Replacement: convert2Ptr(`/v2/\1`)}, false},
+		{"replacement missing group", RuleTypeReplaceBody, GeneralRule{Pattern: convert2Ptr(`^/v1/(.*)$`), Replacement: convert2Ptr(`/v2/\2`)}, true},
+		{"add header value is literal", RuleTypeAddHeader, GeneralRule{Header: convert2Ptr("X"), Replacement: convert2Ptr(`\1`)}, false},
+		{"unknown type", "", GeneralRule{Pattern: convert2Ptr(`x`), Replacement: convert2Ptr(`\1`)}, true},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateRulePattern(tt.rule_type, tt.params)
+			assert.Equal(t, tt.wantErr, err != nil, "error = %v", err)
+		})
+	}
+}
+
+func TestClient_AddRuleInvalidPattern(t *testing.T) {
+	server := createCommandServer(map[string][]string{})
+	defer server.Close()
+	client := createClientForUnit(server.Server, "baz")
+
+	_, err := client.AddRule(RuleTypeModifyURL, "rule", GeneralRule{Pattern: convert2Ptr(`^/(.*)`), Replacement: convert2Ptr(`/\3`)})
+	assert.ErrorContains(t, err, "references group 3")
+
+	_, err = client.ModifyRule("rule", GeneralRule{Pattern: convert2Ptr(`(?=x)`)})
+	assert.ErrorContains(t, err, "lookahead")
+	assert.Empty(t, server.Commands)
+}
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+)
+
+type RulePhase string
+
+const (
+	RulePhasePreProcess RulePhase = "preprocess"
+	RulePhaseRequest    RulePhase = "request"
+	RulePhaseResponse   RulePhase = "response"
+	RulePhaseMatchBody  RulePhase = "matchbody"
+	RulePhaseRealServer RulePhase = "realserver"
+)
+
+type RulePrecedenceResponse struct {
+	*LoadMasterResponse
+	Rules []string
+}
+
+func (vs *VirtualService) rulePrecedence(phase RulePhase) ([]string, error) {
+	switch phase {
+	case RulePhasePreProcess:
+		return vs.MatchRules, nil
+	case RulePhaseMatchBody:
+		return vs.MatchBodyRules, nil
+	case RulePhaseRequest:
+		if vs.VirtualServiceParameters == nil || vs.VirtualServiceParametersAdvancedProperties == nil {
+			return nil, nil
+		}
+		return vs.RequestRules, nil
+	case RulePhaseResponse:
+		if vs.VirtualServiceParameters == nil || vs.VirtualServiceParametersAdvancedProperties == nil {
+			return nil, nil
+		}
+		return vs.ResponseRules, nil
+	case RulePhaseRealServer:
+		// RuleList reports the real server rules in precedence order.
+		if vs.VirtualServiceParameters == nil || vs.VirtualServiceParametersRealServers == nil {
+			return nil, nil
+		}
+		return strings.Fields(vs.RuleList), nil
+	}
+
+	return nil, fmt.Errorf("unknown rule phase %q", phase)
+}
+
+func rulePrecedenceParameters(phase RulePhase, rule string, position int32) (VirtualServiceParameters, error) {
+	switch phase {
+	case RulePhasePreProcess:
+		return VirtualServiceParameters{VirtualServiceParametersAdvancedProperties: &VirtualServiceParametersAdvancedProperties{PreProcPrecedence: rule, PreProcPrecedencePos: &position}}, nil
+	case RulePhaseRequest:
+		return VirtualServiceParameters{VirtualServiceParametersAdvancedProperties: &VirtualServiceParametersAdvancedProperties{RequestPrecedence: rule, RequestPrecedencePos: &position}}, nil
+	case RulePhaseResponse:
+		return VirtualServiceParameters{VirtualServiceParametersAdvancedProperties: &VirtualServiceParametersAdvancedProperties{ResponsePrecedence: rule, ResponsePrecedencePos: &position}}, nil
+	case RulePhaseMatchBody:
+		return VirtualServiceParameters{VirtualServiceParametersAdvancedProperties: &VirtualServiceParametersAdvancedProperties{MatchBodyPrecedence: rule, MatchBodyPrecedencePos: &position}}, nil
+	case RulePhaseRealServer:
+		return VirtualServiceParameters{VirtualServiceParametersRealServers: &VirtualServiceParametersRealServers{RSRulePrecedence: rule, RSRulePrecedencePos: &position}}, nil
+	}
+
+	return VirtualServiceParameters{}, fmt.Errorf("unknown rule phase %q", phase)
+}
+
+// ShowRulePrecedence returns the rules assigned to the virtual service for the given phase in precedence order.
+func (c *Client) ShowRulePrecedence(vs_identifier string, phase RulePhase) (*RulePrecedenceResponse, error) {
+	slog.Debug("Showing rule precedence", "vs_identifier", vs_identifier, "phase", phase)
+
+	response, err := c.ShowVirtualService(vs_identifier)
+	if err != nil {
+		return nil, err
+	}
+	if response.VirtualService == nil {
+		return nil, fmt.Errorf("virtual service %s not found", vs_identifier)
+	}
+
+	rules, err := response.rulePrecedence(phase)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RulePrecedenceResponse{LoadMasterResponse: response.LoadMasterResponse, Rules: rules}, nil
+}
+
+// MoveRulePrecedence moves an assigned rule to the given position of the phase. Positions start at 1.
+func (c *Client) MoveRulePrecedence(vs_identifier string, phase RulePhase, rule string, position int) (*RulePrecedenceResponse, error) {
+	slog.Debug("Moving rule precedence", "vs_identifier", vs_identifier, "phase", phase, "rule", rule, "position", position)
+
+	current, err := c.ShowRulePrecedence(vs_identifier, phase)
+	if err != nil {
+		return nil, err
+	}
+
+	index := slices.Index(current.Rules, rule)
+	if index < 0 {
+		return nil, fmt.Errorf("rule %s is not assigned to virtual service %s", rule, vs_identifier)
+	}
+	if position < 1 || position > len(current.Rules) {
+		return nil, fmt.Errorf("position %d is out of range 1-%d", position, len(current.Rules))
+	}
+
+	desired := slices.Delete(slices.Clone(current.Rules), index, index+1)
+	desired = slices.Insert(desired, position-1, rule)
+
+	return c.applyRulePrecedence(vs_identifier, phase, current.Rules, desired)
+}
+
+// SetRulePrecedence sets the complete order of the rules of the phase.
+// The rules must be exactly the rules currently assigned to the phase.
+func (c *Client) SetRulePrecedence(vs_identifier string, phase RulePhase, rules []string) (*RulePrecedenceResponse, error) {
+	slog.Debug("Setting rule precedence", "vs_identifier", vs_identifier, "phase", phase, "rules", rules)
+
+	current, err := c.ShowRulePrecedence(vs_identifier, phase)
+	if err != nil {
+		return nil, err
+	}
+
+	sorted_current := slices.Sorted(slices.Values(current.Rules))
+	sorted_desired := slices.Sorted(slices.Values(rules))
+	if !slices.Equal(sorted_current, sorted_desired) {
+		return nil, fmt.Errorf("rules %v do not match the assigned rules %v of virtual service %s", rules, current.Rules, vs_identifier)
+	}
+
+	return c.applyRulePrecedence(vs_identifier, phase, current.Rules, rules)
+}
+
+// applyRulePrecedence issues a modvs call for every position where current and desired differ,
+// and verifies the resulting order afterwards.
+func (c *Client) applyRulePrecedence(vs_identifier string, phase RulePhase, current []string, desired []string) (*RulePrecedenceResponse, error) {
+	order := slices.Clone(current)
+	for position, rule := range desired {
+		if order[position] == rule {
+			continue
+		}
+
+		parameters, err := rulePrecedenceParameters(phase, rule, int32(position+1))
+		if err != nil {
+			return nil, err
+		}
+		_, err = c.ModifyVirtualService(vs_identifier, parameters)
+		if err != nil {
+			return nil, err
+		}
+
+		index := slices.Index(order, rule)
+		order = slices.Delete(order, index, index+1)
+		order = slices.Insert(order, position, rule)
+	}
+
+	result, err := c.ShowRulePrecedence(vs_identifier, phase)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Equal(result.Rules, desired) {
+		return nil, fmt.Errorf("rule precedence of virtual service %s is %v, expected %v", vs_identifier, result.Rules, desired)
+	}
+
+	return result, nil
+}
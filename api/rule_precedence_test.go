@@ -0,0 +1,67 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_SetRulePrecedence(t *testing.T) {
+	server := createCommandServer(map[string][]string{
+		"showvs": {
+			`{"code": 200, "status": "ok", "Index": 1, "RequestRules": ["a", "b", "c", "d"]}`,
+			`{"code": 200, "status": "ok", "Index": 1, "RequestRules": ["d", "a", "b", "c"]}`,
+		},
+		"modvs": {`{"code": 200, "status": "ok"}`},
+	})
+	defer server.Close()
+	client := createClientForUnit(server.Server, "baz")
+
+	rs, err := client.SetRulePrecedence("1", RulePhaseRequest, []string{"d", "a", "b", "c"})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"showvs", "modvs", "showvs"}, server.Commands)
+	assert.Equal(t, "d", server.Requests[1]["RequestPrecedence"])
+	assert.Equal(t, float64(1), server.Requests[1]["RequestPrecedencePos"])
+	assert.Equal(t, []string{"d", "a", "b", "c"}, rs.Rules)
+
+	_, err = client.SetRulePrecedence("1", RulePhaseRequest, []string{"d", "a"})
+	assert.Error(t, err)
+}
+
+func TestClient_MoveRulePrecedence(t *testing.T) {
+	testCases := []struct {
+		name     string
+		phase    RulePhase
+		current  string
+		after    string
+		field    string
+		position int
+		wantErr  bool
+	}{
+		{"pre process rule", RulePhasePreProcess, `"MatchRules": ["a", "b"]`, `"MatchRules": ["b", "a"]`, "PreProcPrecedence", 2, false},
+		{"real server rule", RulePhaseRealServer, `"RuleList": "a b"`, `"RuleList": "b a"`, "RSRulePrecedence", 2, false},
+		{"verification fails", RulePhaseMatchBody, `"MatchBodyRules": ["a", "b"]`, `"MatchBodyRules": ["a", "b"]`, "MatchBodyPrecedence", 2, true},
+		{"position out of range", RulePhaseResponse, `"ResponseRules": ["a", "b"]`, `"ResponseRules": ["a", "b"]`, "", 3, true},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			server := createCommandServer(map[string][]string{
+				"showvs": {`{"code": 200, "status": "ok", "Index": 1, ` + tt.current + `}`, `{"code": 200, "status": "ok", "Index": 1, ` + tt.after + `}`},
+				"modvs":  {`{"code": 200, "status": "ok"}`},
+			})
+			defer server.Close()
+			client := createClientForUnit(server.Server, "baz")
+
+			_, err := client.MoveRulePrecedence("1", tt.phase, "a", tt.position)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Client.MoveRulePrecedence() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.field != "" {
+				assert.Equal(t, "b", server.Requests[1][tt.field])
+			}
+		})
+	}
+}
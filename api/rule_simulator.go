@@ -0,0 +1,405 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+type SimulatedRequest struct {
+	Method  string
+	Host    string
+	Path    string
+	Query   string
+	Headers http.Header
+	Body    string
+}
+
+type SimulatedResponse struct {
+	StatusCode int
+	Headers    http.Header
+	Body       string
+}
+
+type SimulatedBackend struct {
+	Rule       string
+	SubVSIndex int32
+	RsIndex    int32
+	Address    string
+	Port       int32
+}
+
+type SimulationStep struct {
+	Phase RulePhase
+	Rule  string
+	// Skipped is set if the flag conditions of the rule were not met.
+	Skipped bool
+	Matched bool
+	Effect  string
+}
+
+type SimulationResult struct {
+	Steps    []SimulationStep
+	Matched  []string
+	Flags    []int32
+	Rejected bool
+	// Backend is the first real server or sub virtual service selected by a real server rule.
+	Backend  *SimulatedBackend
+	Backends []SimulatedBackend
+	Request  SimulatedRequest
+	Response *SimulatedResponse
+}
+
+type simulation struct {
+	rules  map[string]Rule
+	flags  map[int32]bool
+	result *SimulationResult
+}
+
+// SimulateRules evaluates the rules assigned to the virtual service for a sample request and, if given,
+// a sample response without contacting the LoadMaster. The request and response are not modified,
+// the rewritten copies are part of the result.
+func SimulateRules(vs *VirtualService, rules []Rule, request SimulatedRequest, response *SimulatedResponse) (*SimulationResult, error) {
+	s := &simulation{
+		rules:  map[string]Rule{},
+		flags:  map[int32]bool{},
+		result: &SimulationResult{Request: request},
+	}
+	for _, rule := range rules {
+		s.rules[rule.RuleName()] = rule
+	}
+
+	s.result.Request.Headers = request.Headers.Clone()
+	if s.result.Request.Headers == nil {
+		s.result.Request.Headers = http.Header{}
+	}
+	if response != nil {
+		copied := *response
+		copied.Headers = response.Headers.Clone()
+		if copied.Headers == nil {
+			copied.Headers = http.Header{}
+		}
+		s.result.Response = &copied
+	}
+
+phases:
+	for _, phase := range ruleEvaluationOrder {
+		if (phase == RulePhaseResponse || phase == RulePhaseMatchBody) && s.result.Response == nil {
+			continue
+		}
+
+		names, err := vs.rulePrecedence(phase)
+		if err != nil {
+			return nil, err
+		}
+		if phase == RulePhaseRealServer {
+			if err := s.selectBackend(vs, names); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		for _, name := range names {
+			if err := s.apply(phase, name); err != nil {
+				return nil, err
+			}
+			if s.result.Rejected {
+				break phases
+			}
+		}
+	}
+
+	for flag := int32(1); flag <= 9; flag++ {
+		if s.flags[flag] {
+			s.result.Flags = append(s.result.Flags, flag)
+		}
+	}
+
+	return s.result, nil
+}
+
+func (s *simulation) rule(name string) (GeneralRule, Rule, error) {
+	rule, ok := s.rules[name]
+	if !ok {
+		return GeneralRule{}, nil, fmt.Errorf("rule %s is assigned but not defined", name)
+	}
+
+	return rule.GeneralRule(), rule, nil
+}
+
+func (s *simulation) conditionsMet(rule GeneralRule) bool {
+	if flag := flagValue(rule.OnlyOnFlag); flag != 0 && !s.flags[flag] {
+		return false
+	}
+	if flag := flagValue(rule.OnlyOnNoFlag); flag != 0 && s.flags[flag] {
+		return false
+	}
+
+	return true
+}
+
+func (s *simulation) apply(phase RulePhase, name string) error {
+	general, rule, err := s.rule(name)
+	if err != nil {
+		return err
+	}
+
+	step := SimulationStep{Phase: phase, Rule: name}
+	if !s.conditionsMet(general) {
+		step.Skipped = true
+		s.result.Steps = append(s.result.Steps, step)
+		return nil
+	}
+
+	headers := s.result.Request.Headers
+	if phase == RulePhaseResponse || phase == RulePhaseMatchBody {
+		headers = s.result.Response.Headers
+	}
+
+	switch r := rule.(type) {
+	case MatchContentRule:
+		matched, err := s.matchContent(r, phase)
+		if err != nil {
+			return err
+		}
+		step.Matched = matched
+		if matched {
+			s.result.Matched = append(s.result.Matched, name)
+			if flag := flagValue(r.SetOnMatch); flag != 0 {
+				s.flags[flag] = true
+				step.Effect = fmt.Sprintf("set flag %d", flag)
+			}
+			if r.MustFail != nil && *r.MustFail {
+				s.result.Rejected = true
+				step.Effect = "reject"
+			}
+		}
+	case AddHeaderRule:
+		header := ""
+		if r.Header != nil {
+			header = *r.Header
+		}
+		headers.Add(header, r.Replacement)
+		step.Matched = true
+		step.Effect = fmt.Sprintf("add header %s: %s", header, r.Replacement)
+	case DeleteHeaderRule:
+		pattern, err := compileRulePattern(r.Pattern, true)
+		if err != nil {
+			return err
+		}
+		for header := range headers {
+			if pattern.MatchString(header) {
+				headers.Del(header)
+				step.Matched = true
+				step.Effect = strings.TrimSpace(step.Effect + " delete header " + header)
+			}
+		}
+	case ReplaceHeaderRule:
+		header := ""
+		if r.Header != nil {
+			header = *r.Header
+		}
+		pattern, err := compileRulePattern(r.Pattern, false)
+		if err != nil {
+			return err
+		}
+		values := headers.Values(header)
+		for i, value := range values {
+			if pattern.MatchString(value) {
+				values[i] = pattern.ReplaceAllString(value, convertReplacement(r.Replacement))
+				step.Matched = true
+				step.Effect = fmt.Sprintf("replace header %s: %s", header, values[i])
+			}
+		}
+	case ModifyURLRule:
+		pattern, err := compileRulePattern(r.Pattern, false)
+		if err != nil {
+			return err
+		}
+		url := s.result.Request.Path
+		if s.result.Request.Query != "" {
+			url += "?" + s.result.Request.Query
+		}
+		if pattern.MatchString(url) {
+			url = pattern.ReplaceAllString(url, convertReplacement(r.Replacement))
+			path, query, _ := strings.Cut(url, "?")
+			s.result.Request.Path = path
+			s.result.Request.Query = query
+			step.Matched = true
+			step.Effect = "rewrite url to " + url
+		}
+	case ReplaceBodyRule:
+		case_independent := r.CaseIndependent != nil && *r.CaseIndependent
+		pattern, err := compileRulePattern(r.Pattern, case_independent)
+		if err != nil {
+			return err
+		}
+		body := &s.result.Request.Body
+		if s.result.Response != nil && phase != RulePhasePreProcess && phase != RulePhaseRequest {
+			body = &s.result.Response.Body
+		}
+		if pattern.MatchString(*body) {
+			*body = pattern.ReplaceAllString(*body, convertReplacement(r.Replacement))
+			step.Matched = true
+			step.Effect = "replace body"
+		}
+	}
+
+	s.result.Steps = append(s.result.Steps, step)
+
+	return nil
+}
+
+func (s *simulation) matchContent(rule MatchContentRule, phase RulePhase) (bool, error) {
+	var subject string
+	request := s.result.Request
+	header := ""
+	if rule.Header != nil {
+		header = *rule.Header
+	}
+
+	switch {
+	case strings.EqualFold(header, "body"):
+		subject = request.Body
+		if phase == RulePhaseResponse || phase == RulePhaseMatchBody {
+			subject = s.result.Response.Body
+		}
+	case header != "" && (phase == RulePhaseResponse || phase == RulePhaseMatchBody):
+		subject = s.result.Response.Headers.Get(header)
+	case header != "":
+		subject = request.Headers.Get(header)
+		if strings.EqualFold(header, "host") && subject == "" {
+			subject = request.Host
+		}
+	default:
+		subject = request.Path
+		if rule.IncQuery != nil && *rule.IncQuery && request.Query != "" {
+			subject += "?" + request.Query
+		}
+		if rule.IncHost != nil && *rule.IncHost {
+			subject = request.Host + subject
+		}
+	}
+
+	case_independent := rule.CaseIndependent != nil && *rule.CaseIndependent
+	pattern := rule.Pattern
+	if case_independent {
+		subject = strings.ToLower(subject)
+		pattern = strings.ToLower(pattern)
+	}
+
+	var matched bool
+	switch strings.ToLower(rule.MatchType) {
+	case "prefix":
+		matched = strings.HasPrefix(subject, pattern)
+	case "postfix":
+		matched = strings.HasSuffix(subject, pattern)
+	case "", "regex":
+		compiled, err := compileRulePattern(rule.Pattern, case_independent)
+		if err != nil {
+			return false, err
+		}
+		matched = compiled.MatchString(subject)
+	default:
+		return false, fmt.Errorf("unknown match type %q of rule %s", rule.MatchType, rule.Name)
+	}
+
+	if rule.Negate != nil && *rule.Negate {
+		matched = !matched
+	}
+
+	return matched, nil
+}
+
+func (s *simulation) selectBackend(vs *VirtualService, names []string) error {
+	if len(names) == 0 {
+		names = vs.assignedRealServerRules()
+	}
+
+	for _, name := range names {
+		general, rule, err := s.rule(name)
+		if err != nil {
+			return err
+		}
+
+		step := SimulationStep{Phase: RulePhaseRealServer, Rule: name}
+		if !s.conditionsMet(general) {
+			step.Skipped = true
+			s.result.Steps = append(s.result.Steps, step)
+			continue
+		}
+
+		if match_rule, ok := rule.(MatchContentRule); ok {
+			step.Matched, err = s.matchContent(match_rule, RulePhaseRealServer)
+			if err != nil {
+				return err
+			}
+		}
+		if step.Matched {
+			s.result.Matched = append(s.result.Matched, name)
+			for _, rs := range vs.Rs {
+				if slices.Contains(rs.MatchRules, name) {
+					s.result.Backends = append(s.result.Backends, SimulatedBackend{Rule: name, RsIndex: rs.RsIndex, Address: rs.Address, Port: rs.Port})
+				}
+			}
+			for _, subvs := range vs.subVirtualServices() {
+				if slices.Contains(subvs.matchRules(), name) {
+					s.result.Backends = append(s.result.Backends, SimulatedBackend{Rule: name, SubVSIndex: subvs.VSIndex})
+				}
+			}
+			step.Effect = "select backend"
+		}
+		s.result.Steps = append(s.result.Steps, step)
+	}
+
+	if len(s.result.Backends) > 0 {
+		s.result.Backend = &s.result.Backends[0]
+	}
+
+	return nil
+}
+
+// assignedRealServerRules returns the rules assigned to real servers and sub virtual services in listing order.
+func (vs *VirtualService) assignedRealServerRules() []string {
+	var rules []string
+	for _, rs := range vs.Rs {
+		for _, rule := range rs.MatchRules {
+			if !slices.Contains(rules, rule) {
+				rules = append(rules, rule)
+			}
+		}
+	}
+	for _, subvs := range vs.subVirtualServices() {
+		for _, rule := range subvs.matchRules() {
+			if !slices.Contains(rules, rule) {
+				rules = append(rules, rule)
+			}
+		}
+	}
+
+	return rules
+}
+
+func compileRulePattern(pattern string, case_independent bool) (*regexp.Regexp, error) {
+	if case_independent {
+		pattern = "(?i)" + pattern
+	}
+
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid rule pattern %q: %w", pattern, err)
+	}
+
+	return compiled, nil
+}
+
+var replacementBackReference = regexp.MustCompile(`\\([0-9])`)
+
+// convertReplacement translates LoadMaster back-references (\1) into the Go syntax (${1}).
+func convertReplacement(replacement string) string {
+	replacement = strings.ReplaceAll(replacement, "$", "$$")
+
+	return replacementBackReference.ReplaceAllString(replacement, "$${$1}")
+}
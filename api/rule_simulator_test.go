@@ -0,0 +1,101 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimulateRules(t *testing.T) {
+	rules := []Rule{
+		MatchContentRule{Name: "is_api", MatchType: "prefix", Pattern: "/API/", CaseIndependent: convert2Ptr(true), SetOnMatch: convert2Ptr(int32(1))},
+		MatchContentRule{Name: "not_admin", MatchType: "regex", Pattern: "^/admin", Negate: convert2Ptr(true), SetOnMatch: convert2Ptr(int32(2))},
+		MatchContentRule{Name: "host_rule", MatchType: "regex", Pattern: `^example\.com/api/v1/users\?id=1$`, IncHost: convert2Ptr(true), IncQuery: convert2Ptr(true)},
+		MatchContentRule{Name: "json", MatchType: "postfix", Header: convert2Ptr("Content-Type"), Pattern: "json"},
+		ModifyURLRule{Name: "v1_to_v2", Pattern: "^/api/v1/(.*)$", Replacement: `/api/v2/\1`, OnlyOnFlag: convert2Ptr(int32(1))},
+		AddHeaderRule{Name: "add_api", Header: convert2Ptr("X-Api"), Replacement: "yes", OnlyOnFlag: convert2Ptr(int32(1))},
+		AddHeaderRule{Name: "add_never", Header: convert2Ptr("X-Never"), Replacement: "yes", OnlyOnNoFlag: convert2Ptr(int32(2))},
+		DeleteHeaderRule{Name: "del_debug", Pattern: "^x-debug"},
+		ReplaceHeaderRule{Name: "rewrite_location", Header: convert2Ptr("Location"), Pattern: "http://", Replacement: "https://"},
+		ReplaceBodyRule{Name: "rewrite_body", Pattern: "INTERNAL", Replacement: "public", CaseIndependent: convert2Ptr(true)},
+	}
+	vs := &VirtualService{
+		Index:          1,
+		MatchRules:     []string{"is_api", "not_admin", "host_rule"},
+		MatchBodyRules: []string{"rewrite_body"},
+		Rs:             []RealServer{{RsIndex: 7, Address: "10.0.0.100", Port: 80, MatchRules: []string{"json"}}},
+		VirtualServiceParameters: &VirtualServiceParameters{
+			VirtualServiceParametersAdvancedProperties: &VirtualServiceParametersAdvancedProperties{
+				RequestRules:  []string{"v1_to_v2", "add_api", "add_never", "del_debug"},
+				ResponseRules: []string{"rewrite_location"},
+			},
+			VirtualServiceParametersRealServers: &VirtualServiceParametersRealServers{
+				SubVS: []SubVirtualService{{VSIndex: 2, VirtualService: &VirtualService{MatchRules: []string{"is_api"}}}},
+			},
+		},
+	}
+
+	request := SimulatedRequest{
+		Method:  "GET",
+		Host:    "example.com",
+		Path:    "/api/v1/users",
+		Query:   "id=1",
+		Headers: http.Header{"Content-Type": {"application/json"}, "X-Debug-Token": {"1"}},
+	}
+	response := &SimulatedResponse{
+		StatusCode: 302,
+		Headers:    http.Header{"Location": {"http://example.com/"}},
+		Body:       "internal body",
+	}
+
+	result, err := SimulateRules(vs, rules, request, response)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"is_api", "not_admin", "host_rule", "json", "is_api"}, result.Matched)
+	assert.Equal(t, []int32{1, 2}, result.Flags)
+	assert.False(t, result.Rejected)
+
+	assert.Equal(t, "/api/v2/users", result.Request.Path)
+	assert.Equal(t, "id=1", result.Request.Query)
+	assert.Equal(t, "yes", result.Request.Headers.Get("X-Api"))
+	assert.Empty(t, result.Request.Headers.Get("X-Never"))
+	assert.Empty(t, result.Request.Headers.Get("X-Debug-Token"))
+	assert.Equal(t, "1", request.Headers.Get("X-Debug-Token"))
+
+	require.NotNil(t, result.Backend)
+	assert.Equal(t, int32(7), result.Backend.RsIndex)
+	assert.Equal(t, "json", result.Backend.Rule)
+	require.Len(t, result.Backends, 2)
+	assert.Equal(t, int32(2), result.Backends[1].SubVSIndex)
+
+	assert.Equal(t, "https://example.com/", result.Response.Headers.Get("Location"))
+	assert.Equal(t, "public body", result.Response.Body)
+	assert.Equal(t, "http://example.com/", response.Headers.Get("Location"))
+}
+
+func TestSimulateRulesRejectAndErrors(t *testing.T) {
+	rules := []Rule{
+		MatchContentRule{Name: "block", MatchType: "regex", Pattern: "^/private", MustFail: convert2Ptr(true)},
+		AddHeaderRule{Name: "after", Header: convert2Ptr("X-After"), Replacement: "1"},
+	}
+	vs := &VirtualService{Index: 1, MatchRules: []string{"block", "after"}}
+
+	result, err := SimulateRules(vs, rules, SimulatedRequest{Path: "/private/data"}, nil)
+	require.NoError(t, err)
+	assert.True(t, result.Rejected)
+	assert.Equal(t, []string{"block"}, result.Matched)
+	assert.Empty(t, result.Request.Headers.Get("X-After"))
+
+	_, err = SimulateRules(&VirtualService{MatchRules: []string{"unknown"}}, rules, SimulatedRequest{}, nil)
+	assert.Error(t, err)
+
+	_, err = SimulateRules(&VirtualService{MatchRules: []string{"broken"}}, []Rule{MatchContentRule{Name: "broken", Pattern: "("}}, SimulatedRequest{}, nil)
+	assert.Error(t, err)
+}
+
+func TestConvertReplacement(t *testing.T) {
+	assert.Equal(t, "/v2/${1}/${2}", convertReplacement(`/v2/\1/\2`))
+	assert.Equal(t, "$$5", convertReplacement("$5"))
+}
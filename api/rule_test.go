@@ -790,3 +790,67 @@ func TestIntegration_ReplaceResponseBodyRules(t *testing.T) {
 	})
 
 }
+
+func TestClient_TypedRuleRoundTrip(t *testing.T) {
+	rules := `{"code": 200, "status": "ok",
+		"MatchContentRule": [ { "name": "match", "matchtype": "Regex", "addhost": true, "CaseIndependent": true, "negate": false, "IncludeQuery": true, "header": "Host", "pattern": "^/api", "SetFlagOnMatch": 3, "onlyonflag": 1, "mustfail": false } ],
+		"AddHeaderRule": [ { "name": "addheader", "header": "X-Added", "HeaderValue": "yes", "onlyonflag": 3 } ],
+		"DeleteHeaderRule": [ { "name": "delheader", "pattern": "X-Remove" } ],
+		"ReplaceHeaderRule": [ { "name": "replaceheader", "header": "X-Header", "replacement": "new", "pattern": "old" } ],
+		"ModifyURLRule": [ { "name": "modifyurl", "replacement": "/v2/\\1", "pattern": "^/v1/(.*)" } ],
+		"ReplaceBodyRule": [ { "name": "replacebody", "replacement": "new", "pattern": "old", "caseindependent": true, "onlyonnoflag": 2 } ]
+	}`
+
+	server := createCommandServer(map[string][]string{
+		"showrule": {rules},
+		"addrule":  {rules},
+	})
+	defer server.Close()
+	client := createClientForUnit(server.Server, "baz")
+
+	response, err := client.ListRule()
+	require.NoError(t, err)
+	read := response.Rules()
+	require.Len(t, read, 6)
+
+	for _, rule := range read {
+		written, err := client.AddTypedRule(rule)
+		require.NoError(t, err)
+		assert.Equal(t, rule, written)
+	}
+
+	requests := server.Requests[1:]
+	assert.Equal(t, map[string]any{"cmd": "addrule", "apiuser": "foo", "apipass": "baz", "type": "0", "name": "match", "matchtype": "regex", "inchost": true, "nocase": true, "negate": false, "incquery": true, "header": "Host", "pattern": "^/api", "setonmatch": float64(3), "onlyonflag": float64(1), "mustfail": false}, requests[0])
+	assert.Equal(t, map[string]any{"cmd": "addrule", "apiuser": "foo", "apipass": "baz", "type": "1", "name": "addheader", "header": "X-Added", "replacement": "yes", "onlyonflag": float64(3)}, requests[1])
+	assert.Equal(t, map[string]any{"cmd": "addrule", "apiuser": "foo", "apipass": "baz", "type": "2", "name": "delheader", "pattern": "X-Remove"}, requests[2])
+	assert.Equal(t, "3", requests[3]["type"])
+	assert.Equal(t, "4", requests[4]["type"])
+	assert.Equal(t, "^/v1/(.*)", requests[4]["pattern"])
+	assert.Equal(t, "5", requests[5]["type"])
+	assert.Equal(t, true, requests[5]["caseindependent"])
+}
+
+func TestClient_ShowTypedRule(t *testing.T) {
+	server := createCommandServer(map[string][]string{
+		"showrule": {`{"code": 200, "status": "ok", "AddHeaderRule": [ { "name": "rule1", "header": "X-Added", "HeaderValue": "yes" } ]}`},
+		"modrule":  {`{"code": 200, "status": "ok", "AddHeaderRule": [ { "name": "rule1", "header": "X-Added", "HeaderValue": "yes" } ]}`},
+	})
+	defer server.Close()
+	client := createClientForUnit(server.Server, "baz")
+
+	rule, err := client.ShowTypedRule("rule1")
+	require.NoError(t, err)
+	assert.Equal(t, AddHeaderRule{Name: "rule1", Header: convert2Ptr("X-Added"), Replacement: "yes"}, rule)
+
+	_, err = client.ShowTypedRule("unknown")
+	assert.Error(t, err)
+
+	_, err = client.ModifyTypedRule(ModifyURLRule{Name: "rule1", Pattern: "a"})
+	assert.Error(t, err)
+	assert.NotContains(t, server.Commands, "modrule")
+
+	modified, err := client.ModifyTypedRule(AddHeaderRule{Name: "rule1", Header: convert2Ptr("X-Added"), Replacement: "yes"})
+	require.NoError(t, err)
+	assert.Equal(t, RuleTypeAddHeader, modified.RuleType())
+	assert.Equal(t, []string{"showrule", "showrule", "showrule", "showrule", "modrule"}, server.Commands)
+}
@@ -0,0 +1,221 @@
+package api
+
+import (
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+)
+
+// RuleUsage is a single assignment of a rule. Real server rules are either assigned to a real server
+// (RsIndex is set) or to a sub virtual service (SubVSIndex is set).
+type RuleUsage struct {
+	Rule       string
+	Phase      RulePhase
+	VSIndex    int32
+	RsIndex    int32
+	SubVSIndex int32
+	// Position is the 1-based position of the rule within the phase of the virtual service.
+	Position int32
+}
+
+type RuleUsageIndex struct {
+	*LoadMasterResponse
+	Usages map[string][]RuleUsage
+	// Orphaned lists the defined rules which are not assigned anywhere.
+	Orphaned []string
+	// Undefined lists the assigned rules which are not defined.
+	Undefined []string
+}
+
+type RuleInUseError struct {
+	Rule  string
+	Users []RuleUsage
+}
+
+func (e *RuleInUseError) Error() string {
+	users := make([]string, 0, len(e.Users))
+	for _, usage := range e.Users {
+		users = append(users, usage.String())
+	}
+
+	return fmt.Sprintf("rule %s is still used by %s", e.Rule, strings.Join(users, ", "))
+}
+
+func (u RuleUsage) String() string {
+	switch {
+	case u.RsIndex != 0:
+		return fmt.Sprintf("real server %d of virtual service %d", u.RsIndex, u.VSIndex)
+	case u.SubVSIndex != 0:
+		return fmt.Sprintf("sub virtual service %d of virtual service %d", u.SubVSIndex, u.VSIndex)
+	}
+
+	return fmt.Sprintf("%s rules of virtual service %d", u.Phase, u.VSIndex)
+}
+
+// IndexRuleUsage cross-references the rules with the pre-process, request, response, body and real server
+// rule assignments of the virtual services, sub virtual services and real servers.
+func IndexRuleUsage(rules []Rule, services []VirtualService) *RuleUsageIndex {
+	index := &RuleUsageIndex{Usages: map[string][]RuleUsage{}}
+	add := func(usage RuleUsage) {
+		index.Usages[usage.Rule] = append(index.Usages[usage.Rule], usage)
+	}
+
+	for i := range services {
+		vs := &services[i]
+		for _, phase := range []RulePhase{RulePhasePreProcess, RulePhaseRequest, RulePhaseResponse, RulePhaseMatchBody} {
+			names, _ := vs.rulePrecedence(phase)
+			for position, name := range names {
+				add(RuleUsage{Rule: name, Phase: phase, VSIndex: vs.Index, Position: int32(position + 1)})
+			}
+		}
+		for _, rs := range vs.Rs {
+			for _, name := range rs.MatchRules {
+				add(RuleUsage{Rule: name, Phase: RulePhaseRealServer, VSIndex: vs.Index, RsIndex: rs.RsIndex})
+			}
+		}
+		for _, subvs := range vs.subVirtualServices() {
+			for _, name := range subvs.matchRules() {
+				add(RuleUsage{Rule: name, Phase: RulePhaseRealServer, VSIndex: vs.Index, SubVSIndex: subvs.VSIndex})
+			}
+		}
+	}
+
+	defined := map[string]bool{}
+	for _, rule := range rules {
+		defined[rule.RuleName()] = true
+		if len(index.Usages[rule.RuleName()]) == 0 {
+			index.Orphaned = append(index.Orphaned, rule.RuleName())
+		}
+	}
+	for i := range services {
+		for _, name := range services[i].assignedRules() {
+			if !defined[name] {
+				defined[name] = true
+				index.Undefined = append(index.Undefined, name)
+			}
+		}
+	}
+
+	return index
+}
+
+// Users returns every assignment of the rule.
+func (i *RuleUsageIndex) Users(rule string) []RuleUsage {
+	return i.Usages[rule]
+}
+
+// ShowRuleUsage loads all rules and virtual services and runs IndexRuleUsage on them.
+func (c *Client) ShowRuleUsage() (*RuleUsageIndex, error) {
+	slog.Debug("Showing rule usage")
+
+	rules, err := c.ListRule()
+	if err != nil {
+		return nil, err
+	}
+
+	services, err := c.ListVirtualService()
+	if err != nil {
+		return nil, err
+	}
+
+	index := IndexRuleUsage(rules.Rules(), services.VS)
+	index.LoadMasterResponse = services.LoadMasterResponse
+
+	return index, nil
+}
+
+// SafeDeleteRule deletes a rule only if it is not assigned anywhere. If it is still in use, a *RuleInUseError
+// listing the users is returned, unless detach is set, in which case the rule is removed from every assignment first.
+// If detaching or deleting fails, the removed assignments are restored.
+func (c *Client) SafeDeleteRule(name string, detach bool) (*LoadMasterResponse, error) {
+	slog.Debug("Safely deleting rule", "name", name, "detach", detach)
+	var undo rollback
+
+	index, err := c.ShowRuleUsage()
+	if err != nil {
+		return nil, err
+	}
+
+	users := index.Users(name)
+	if len(users) > 0 && !detach {
+		return nil, &RuleInUseError{Rule: name, Users: users}
+	}
+
+	for _, usage := range users {
+		err := c.detachRule(usage)
+		if err != nil {
+			return nil, undo.run(err)
+		}
+		undo.push(func() error {
+			return c.attachRule(usage)
+		})
+	}
+
+	response, err := c.DeleteRule(name)
+	if err != nil {
+		return nil, undo.run(err)
+	}
+
+	return response, nil
+}
+
+func (c *Client) detachRule(usage RuleUsage) error {
+	vs_identifier := strconv.Itoa(int(usage.VSIndex))
+
+	var err error
+	switch {
+	case usage.RsIndex != 0:
+		_, err = c.DeleteRealServerRule(vs_identifier, RealServer{RsIndex: usage.RsIndex}.identifier(), usage.Rule)
+	case usage.SubVSIndex != 0:
+		_, err = c.DeleteSubVirtualServiceRule(vs_identifier, strconv.Itoa(int(usage.SubVSIndex)), usage.Rule)
+	case usage.Phase == RulePhasePreProcess:
+		_, err = c.DeleteVirtualServicePreRule(vs_identifier, usage.Rule)
+	case usage.Phase == RulePhaseRequest:
+		_, err = c.DeleteVirtualServiceRequestRule(vs_identifier, usage.Rule)
+	case usage.Phase == RulePhaseResponse:
+		_, err = c.DeleteVirtualServiceResponseRule(vs_identifier, usage.Rule)
+	case usage.Phase == RulePhaseMatchBody:
+		_, err = c.DeleteVirtualServiceResponseBodyRule(vs_identifier, usage.Rule)
+	default:
+		err = fmt.Errorf("cannot detach rule %s from %s", usage.Rule, usage)
+	}
+
+	return err
+}
+
+// attachRule reverts detachRule. Rules of the virtual service phases are moved back to their previous position.
+func (c *Client) attachRule(usage RuleUsage) error {
+	vs_identifier := strconv.Itoa(int(usage.VSIndex))
+
+	var err error
+	switch {
+	case usage.RsIndex != 0:
+		_, err = c.AddRealServerRule(vs_identifier, RealServer{RsIndex: usage.RsIndex}.identifier(), usage.Rule)
+		return err
+	case usage.SubVSIndex != 0:
+		_, err = c.AddSubVirtualServiceRule(vs_identifier, strconv.Itoa(int(usage.SubVSIndex)), usage.Rule)
+		return err
+	case usage.Phase == RulePhasePreProcess:
+		_, err = c.AddVirtualServicePreRule(vs_identifier, usage.Rule)
+	case usage.Phase == RulePhaseRequest:
+		_, err = c.AddVirtualServiceRequestRule(vs_identifier, usage.Rule)
+	case usage.Phase == RulePhaseResponse:
+		_, err = c.AddVirtualServiceResponseRule(vs_identifier, usage.Rule)
+	case usage.Phase == RulePhaseMatchBody:
+		_, err = c.AddVirtualServiceResponseBodyRule(vs_identifier, usage.Rule)
+	default:
+		return fmt.Errorf("cannot attach rule %s to %s", usage.Rule, usage)
+	}
+	if err != nil {
+		return err
+	}
+
+	parameters, err := rulePrecedenceParameters(usage.Phase, usage.Rule, usage.Position)
+	if err != nil {
+		return err
+	}
+	_, err = c.ModifyVirtualService(vs_identifier, parameters)
+
+	return err
+}
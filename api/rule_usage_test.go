@@ -0,0 +1,108 @@
+package api
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndexRuleUsage(t *testing.T) {
+	rules := []Rule{
+		MatchContentRule{Name: "api"},
+		AddHeaderRule{Name: "header"},
+		ReplaceBodyRule{Name: "body"},
+		MatchContentRule{Name: "orphan"},
+	}
+	services := []VirtualService{
+		{
+			Index:          1,
+			MatchRules:     []string{"missing", "api"},
+			MatchBodyRules: []string{"body"},
+			Rs:             []RealServer{{RsIndex: 3, MatchRules: []string{"api"}}},
+			VirtualServiceParameters: &VirtualServiceParameters{
+				VirtualServiceParametersAdvancedProperties: &VirtualServiceParametersAdvancedProperties{RequestRules: []string{"header"}},
+				VirtualServiceParametersRealServers:        &VirtualServiceParametersRealServers{SubVS: []SubVirtualService{{VSIndex: 2, VirtualService: &VirtualService{MatchRules: []string{"api"}}}}},
+			},
+		},
+	}
+
+	index := IndexRuleUsage(rules, services)
+
+	assert.Equal(t, []RuleUsage{
+		{Rule: "api", Phase: RulePhasePreProcess, VSIndex: 1, Position: 2},
+		{Rule: "api", Phase: RulePhaseRealServer, VSIndex: 1, RsIndex: 3},
+		{Rule: "api", Phase: RulePhaseRealServer, VSIndex: 1, SubVSIndex: 2},
+	}, index.Users("api"))
+	assert.Equal(t, []RuleUsage{{Rule: "header", Phase: RulePhaseRequest, VSIndex: 1, Position: 1}}, index.Users("header"))
+	assert.Equal(t, []string{"orphan"}, index.Orphaned)
+	assert.Equal(t, []string{"missing"}, index.Undefined)
+}
+
+func TestClient_SafeDeleteRule(t *testing.T) {
+	listvs := `{"code": 200, "status": "ok", "VS": [ { "Index": 1, "MatchRules": ["api"], "Rs": [ { "RsIndex": 3, "MatchRules": ["api"] } ] } ]}`
+	showrule := `{"code": 200, "status": "ok", "MatchContentRule": [ { "name": "api", "pattern": "^/api" }, { "name": "orphan", "pattern": "^/" } ]}`
+
+	t.Run("refuses", func(t *testing.T) {
+		server := createCommandServer(map[string][]string{
+			"showrule": {showrule},
+			"listvs":   {listvs},
+		})
+		defer server.Close()
+		client := createClientForUnit(server.Server, "baz")
+
+		_, err := client.SafeDeleteRule("api", false)
+		var in_use *RuleInUseError
+		require.True(t, errors.As(err, &in_use))
+		assert.Len(t, in_use.Users, 2)
+		assert.Equal(t, []string{"showrule", "listvs"}, server.Commands)
+	})
+
+	t.Run("detaches", func(t *testing.T) {
+		server := createCommandServer(map[string][]string{
+			"showrule":   {showrule},
+			"listvs":     {listvs},
+			"delprerule": {`{"code": 200, "status": "ok"}`},
+			"delrsrule":  {`{"code": 200, "status": "ok"}`},
+			"delrule":    {`{"code": 200, "status": "ok"}`},
+		})
+		defer server.Close()
+		client := createClientForUnit(server.Server, "baz")
+
+		_, err := client.SafeDeleteRule("api", true)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"showrule", "listvs", "delprerule", "delrsrule", "delrule"}, server.Commands)
+		assert.Equal(t, "!3", server.Requests[3]["rs"])
+	})
+
+	t.Run("restores on failure", func(t *testing.T) {
+		server := createCommandServer(map[string][]string{
+			"showrule":   {showrule},
+			"listvs":     {listvs},
+			"delprerule": {`{"code": 200, "status": "ok"}`},
+			"delrsrule":  {`!{"code": 400, "status": "fail", "message": "failed"}`},
+			"addprerule": {`{"code": 200, "status": "ok"}`},
+			"modvs":      {`{"code": 200, "status": "ok", "Index": 1}`},
+		})
+		defer server.Close()
+		client := createClientForUnit(server.Server, "baz")
+
+		_, err := client.SafeDeleteRule("api", true)
+		assert.Error(t, err)
+		assert.Equal(t, []string{"showrule", "listvs", "delprerule", "delrsrule", "addprerule", "modvs"}, server.Commands)
+	})
+
+	t.Run("unused", func(t *testing.T) {
+		server := createCommandServer(map[string][]string{
+			"showrule": {showrule},
+			"listvs":   {listvs},
+			"delrule":  {`{"code": 200, "status": "ok"}`},
+		})
+		defer server.Close()
+		client := createClientForUnit(server.Server, "baz")
+
+		_, err := client.SafeDeleteRule("orphan", false)
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"fmt"
+	"log/slog"
+)
+
+// VirtualServiceStatistics holds the counters of a virtual service as reported by the stats command.
+type VirtualServiceStatistics struct {
+	Index       int32  `json:"Index"`
+	Address     string `json:"VSAddress"`
+	Port        string `json:"VSPort"`
+	Protocol    string `json:"VSProt"`
+	Status      string `json:"Status"`
+	TotalConns  int64  `json:"TotalConns"`
+	ActiveConns int64  `json:"ActiveConns"`
+	ConnsPerSec int64  `json:"ConnsPerSec"`
+	// ErrorConns counts the connections which failed, for example because no real server was available.
+	ErrorConns int64 `json:"ErrorConns"`
+}
+
+type StatisticsResponse struct {
+	*LoadMasterResponse
+	VS []VirtualServiceStatistics `json:"Vs"`
+}
+
+func (c *Client) ShowStatistics() (*StatisticsResponse, error) {
+	slog.Debug("Showing statistics")
+	payload := struct {
+		*LoadMasterRequest
+	}{
+		LoadMasterRequest: &LoadMasterRequest{
+			Command: "stats",
+		},
+	}
+
+	response, err := sendRequest(c, payload, StatisticsResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (c *Client) ShowVirtualServiceStatistics(vs_index int32) (*VirtualServiceStatistics, error) {
+	slog.Debug("Showing virtual service statistics", "vs_index", vs_index)
+
+	response, err := c.ShowStatistics()
+	if err != nil {
+		return nil, err
+	}
+	for i := range response.VS {
+		if response.VS[i].Index == vs_index {
+			return &response.VS[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("no statistics found for virtual service %d", vs_index)
+}
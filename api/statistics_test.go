@@ -0,0 +1,25 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_ShowVirtualServiceStatistics(t *testing.T) {
+	server := createCommandServer(map[string][]string{
+		"stats": {`{"code": 200, "status": "ok", "Vs": [ { "Index": 1, "VSAddress": "10.0.0.1", "VSPort": "80", "TotalConns": 100, "ErrorConns": 2 }, { "Index": 2, "TotalConns": 5 } ]}`},
+	})
+	defer server.Close()
+	client := createClientForUnit(server.Server, "baz")
+
+	statistics, err := client.ShowVirtualServiceStatistics(1)
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.1", statistics.Address)
+	assert.Equal(t, int64(100), statistics.TotalConns)
+	assert.Equal(t, int64(2), statistics.ErrorConns)
+
+	_, err = client.ShowVirtualServiceStatistics(3)
+	assert.Error(t, err)
+}
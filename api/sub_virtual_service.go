@@ -1,6 +1,11 @@
 package api
 
-import "log/slog"
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+)
 
 type SubVirtualService struct {
 	*VirtualService
@@ -99,3 +104,200 @@ func (c *Client) DeleteSubVirtualService(identifier string) (*LoadMasterResponse
 
 	return response, nil
 }
+
+type ListSubVirtualServiceResponse struct {
+	*LoadMasterResponse
+	SubVS []SubVirtualService
+}
+
+type VirtualServiceTree struct {
+	*VirtualService
+	SubVS []SubVirtualServiceTree
+}
+
+type SubVirtualServiceTree struct {
+	*SubVirtualService
+	Rs []RealServer
+}
+
+func (vs *VirtualService) subVirtualServices() []SubVirtualService {
+	if vs == nil || vs.VirtualServiceParameters == nil || vs.VirtualServiceParametersRealServers == nil {
+		return nil
+	}
+
+	return vs.SubVS
+}
+
+func (s SubVirtualService) matchRules() []string {
+	if s.VirtualService == nil {
+		return nil
+	}
+
+	return s.MatchRules
+}
+
+// ListSubVirtualService returns the sub virtual services of the given parent in the order the LoadMaster reports them.
+func (c *Client) ListSubVirtualService(vs_identifier string) (*ListSubVirtualServiceResponse, error) {
+	slog.Debug("Listing sub virtual services", "vs_identifier", vs_identifier)
+
+	response, err := c.ShowVirtualService(vs_identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ListSubVirtualServiceResponse{
+		LoadMasterResponse: response.LoadMasterResponse,
+		SubVS:              response.subVirtualServices(),
+	}, nil
+}
+
+// CreateSubVirtualService adds a sub virtual service to the given parent and returns the created sub virtual service.
+// Unlike AddSubVirtualService, the index of the new sub virtual service is determined by comparing the children
+// of the parent before and after the creation.
+func (c *Client) CreateSubVirtualService(vs_identifier string, parameters VirtualServiceParameters) (*ShowSubVirtualServiceResponse, error) {
+	slog.Debug("Creating sub virtual service", "vs_identifier", vs_identifier)
+
+	before, err := c.ShowVirtualService(vs_identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	known := map[int32]bool{}
+	for _, subvs := range before.subVirtualServices() {
+		known[subvs.VSIndex] = true
+	}
+
+	after, err := c.AddSubVirtualService(vs_identifier, parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	var created []int32
+	if after.SubVirtualService != nil {
+		for _, subvs := range after.VirtualService.subVirtualServices() {
+			if !known[subvs.VSIndex] {
+				created = append(created, subvs.VSIndex)
+			}
+		}
+	}
+
+	if len(created) != 1 {
+		return nil, fmt.Errorf("could not determine index of new sub virtual service in virtual service %s, found %d new entries", vs_identifier, len(created))
+	}
+
+	return c.ShowSubVirtualService(strconv.Itoa(int(created[0])))
+}
+
+// ReorderSubVirtualService changes the order in which the sub virtual services of a parent are evaluated.
+// The LoadMaster selects a sub virtual service by walking the real server rules in their precedence order,
+// so the rules assigned to each sub virtual service are moved to the position given by order.
+// Sub virtual services not contained in order keep their rules behind the reordered ones.
+// The virtual service is read again afterwards and an error is returned if its sub virtual services are not in order.
+func (c *Client) ReorderSubVirtualService(vs_identifier string, order []int32) (*VirtualServiceResponse, error) {
+	slog.Debug("Reordering sub virtual services", "vs_identifier", vs_identifier, "order", order)
+
+	response, err := c.ShowVirtualService(vs_identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	children := map[int32]SubVirtualService{}
+	for _, subvs := range response.subVirtualServices() {
+		children[subvs.VSIndex] = subvs
+	}
+
+	var rules []string
+	for _, index := range order {
+		subvs, ok := children[index]
+		if !ok {
+			return nil, fmt.Errorf("sub virtual service %d not found in virtual service %s", index, vs_identifier)
+		}
+		rules = append(rules, subvs.matchRules()...)
+	}
+
+	for position, rule := range rules {
+		parameters, err := rulePrecedenceParameters(RulePhaseRealServer, rule, int32(position+1))
+		if err != nil {
+			return nil, err
+		}
+		_, err = c.ModifyVirtualService(vs_identifier, parameters)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	response, err = c.ShowVirtualService(vs_identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	var current []int32
+	for _, subvs := range response.subVirtualServices() {
+		if slices.Contains(order, subvs.VSIndex) {
+			current = append(current, subvs.VSIndex)
+		}
+	}
+	if !slices.Equal(current, order) {
+		return nil, fmt.Errorf("sub virtual services of virtual service %s are in order %v, expected %v", vs_identifier, current, order)
+	}
+
+	return response, nil
+}
+
+// MoveRealServer moves a real server from one (sub) virtual service to another.
+// The real server is added to the target with its current parameters and rules before it is removed from the source.
+// If any step fails, the real server is removed from the target again.
+func (c *Client) MoveRealServer(from_vs_identifier string, rs_identifier string, to_vs_identifier string) (*ListRealServerResponse, error) {
+	slog.Debug("Moving real server", "from_vs_identifier", from_vs_identifier, "rs_identifier", rs_identifier, "to_vs_identifier", to_vs_identifier)
+	var undo rollback
+
+	current, err := c.ShowRealServer(from_vs_identifier, rs_identifier)
+	if err != nil {
+		return nil, err
+	}
+	if len(current.Rs) == 0 {
+		return nil, fmt.Errorf("real server %s not found in virtual service %s", rs_identifier, from_vs_identifier)
+	}
+
+	response, moved, err := c.addRealServerCopy(to_vs_identifier, current.Rs[0])
+	if err != nil {
+		return nil, err
+	}
+	undo.push(func() error {
+		_, err := c.DeleteRealServer(to_vs_identifier, moved)
+		return err
+	})
+
+	_, err = c.DeleteRealServer(from_vs_identifier, rs_identifier)
+	if err != nil {
+		return nil, undo.run(err)
+	}
+
+	return response, nil
+}
+
+// ShowVirtualServiceTree walks the parent, its sub virtual services and their real servers.
+func (c *Client) ShowVirtualServiceTree(vs_identifier string) (*VirtualServiceTree, error) {
+	slog.Debug("Showing virtual service tree", "vs_identifier", vs_identifier)
+
+	response, err := c.ShowVirtualService(vs_identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := &VirtualServiceTree{VirtualService: response.VirtualService}
+	for _, subvs := range response.subVirtualServices() {
+		sub_response, err := c.ShowSubVirtualService(strconv.Itoa(int(subvs.VSIndex)))
+		if err != nil {
+			return nil, err
+		}
+
+		node := SubVirtualServiceTree{SubVirtualService: sub_response.SubVirtualService}
+		if sub_response.SubVirtualService != nil && sub_response.VirtualService != nil {
+			node.Rs = sub_response.Rs
+		}
+		tree.SubVS = append(tree.SubVS, node)
+	}
+
+	return tree, nil
+}
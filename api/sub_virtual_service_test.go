@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestClient_AddSubVirtualService(t *testing.T) {
@@ -162,3 +163,132 @@ func TestIntegration_SubVirtualService(t *testing.T) {
 		assert.Equal(t, "ok", response.Status)
 	})
 }
+
+func TestClient_CreateSubVirtualService(t *testing.T) {
+	server := createCommandServer(map[string][]string{
+		"showvs": {
+			`{"code": 200, "status": "ok", "Index": 1, "SubVS": [ { "VSIndex": 2 } ]}`,
+			`{"code": 200, "status": "ok", "Index": 5, "NickName": "new"}`,
+		},
+		"modvs": {`{"code": 200, "status": "ok", "Index": 1, "SubVS": [ { "VSIndex": 2 }, { "VSIndex": 5 } ]}`},
+	})
+	defer server.Close()
+	client := createClientForUnit(server.Server, "baz")
+
+	rs, err := client.CreateSubVirtualService("1", VirtualServiceParameters{})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"showvs", "modvs", "showvs"}, server.Commands)
+	assert.Equal(t, "5", server.Requests[2]["vs"])
+	assert.Equal(t, int32(5), rs.Index)
+	assert.Equal(t, "new", rs.NickName)
+}
+
+func TestClient_ReorderSubVirtualService(t *testing.T) {
+	before := `{"code": 200, "status": "ok", "Index": 1, "SubVS": [ { "VSIndex": 2, "MatchRules": ["a"] }, { "VSIndex": 3, "MatchRules": ["b", "c"] } ]}`
+	after := `{"code": 200, "status": "ok", "Index": 1, "SubVS": [ { "VSIndex": 3, "MatchRules": ["b", "c"] }, { "VSIndex": 2, "MatchRules": ["a"] } ]}`
+
+	t.Run("success", func(t *testing.T) {
+		server := createCommandServer(map[string][]string{
+			"showvs": {before, after},
+			"modvs":  {`{"code": 200, "status": "ok"}`},
+		})
+		defer server.Close()
+		client := createClientForUnit(server.Server, "baz")
+
+		response, err := client.ReorderSubVirtualService("1", []int32{3, 2})
+		require.NoError(t, err)
+		assert.Equal(t, int32(3), response.subVirtualServices()[0].VSIndex)
+
+		assert.Equal(t, []string{"showvs", "modvs", "modvs", "modvs", "showvs"}, server.Commands)
+		assert.Equal(t, "b", server.Requests[1]["RSRulePrecedence"])
+		assert.Equal(t, float64(1), server.Requests[1]["RSRulePrecedencePos"])
+		assert.Equal(t, "c", server.Requests[2]["RSRulePrecedence"])
+		assert.Equal(t, "a", server.Requests[3]["RSRulePrecedence"])
+		assert.Equal(t, float64(3), server.Requests[3]["RSRulePrecedencePos"])
+
+		_, err = client.ReorderSubVirtualService("1", []int32{4})
+		assert.Error(t, err)
+	})
+
+	t.Run("order not applied", func(t *testing.T) {
+		server := createCommandServer(map[string][]string{
+			"showvs": {before},
+			"modvs":  {`{"code": 200, "status": "ok"}`},
+		})
+		defer server.Close()
+		client := createClientForUnit(server.Server, "baz")
+
+		_, err := client.ReorderSubVirtualService("1", []int32{3, 2})
+		assert.ErrorContains(t, err, "expected [3 2]")
+	})
+}
+
+func TestClient_MoveRealServer(t *testing.T) {
+	showrs := `{"code": 200, "status": "ok", "Rs": [ { "RSIndex": 7, "Addr": "10.0.0.100", "Port": 8080, "DnsName": "app.example.com", "Weight": 500, "MatchRules": ["images"] } ]}`
+	addrs := `{"code": 200, "status": "ok", "Rs": [ { "RSIndex": 9, "Addr": "10.0.0.100", "Port": 8080 } ]}`
+
+	t.Run("success", func(t *testing.T) {
+		server := createCommandServer(map[string][]string{
+			"showrs":    {showrs},
+			"addrs":     {addrs},
+			"addrsrule": {`{"code": 200, "status": "ok"}`},
+			"delrs":     {`{"code": 200, "status": "ok"}`},
+		})
+		defer server.Close()
+		client := createClientForUnit(server.Server, "baz")
+
+		_, err := client.MoveRealServer("2", "!7", "3")
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"showrs", "addrs", "addrsrule", "delrs"}, server.Commands)
+		assert.Equal(t, "3", server.Requests[1]["vs"])
+		assert.Equal(t, "10.0.0.100", server.Requests[1]["rs"])
+		assert.Equal(t, "8080", server.Requests[1]["rsport"])
+		assert.Equal(t, float64(500), server.Requests[1]["Weight"])
+		assert.Equal(t, "app.example.com", server.Requests[1]["DnsName"])
+		assert.Equal(t, "!9", server.Requests[2]["rs"])
+		assert.Equal(t, "images", server.Requests[2]["rule"])
+		assert.Equal(t, "2", server.Requests[3]["vs"])
+		assert.Equal(t, "!7", server.Requests[3]["rs"])
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		server := createCommandServer(map[string][]string{
+			"showrs":    {showrs},
+			"addrs":     {addrs},
+			"addrsrule": {`{"code": 200, "status": "ok"}`},
+			"delrs":     {`!{"code": 400, "status": "fail", "message": "failed"}`, `{"code": 200, "status": "ok"}`},
+		})
+		defer server.Close()
+		client := createClientForUnit(server.Server, "baz")
+
+		_, err := client.MoveRealServer("2", "!7", "3")
+		require.Error(t, err)
+
+		assert.Equal(t, []string{"showrs", "addrs", "addrsrule", "delrs", "delrs"}, server.Commands)
+		assert.Equal(t, "3", server.Requests[4]["vs"])
+		assert.Equal(t, "!9", server.Requests[4]["rs"])
+	})
+}
+
+func TestClient_ShowVirtualServiceTree(t *testing.T) {
+	server := createCommandServer(map[string][]string{
+		"showvs": {
+			`{"code": 200, "status": "ok", "Index": 1, "SubVS": [ { "VSIndex": 2 }, { "VSIndex": 3 } ]}`,
+			`{"code": 200, "status": "ok", "Index": 2, "Rs": [ { "RSIndex": 1, "Addr": "10.0.0.100" } ]}`,
+			`{"code": 200, "status": "ok", "Index": 3, "Rs": [ { "RSIndex": 2, "Addr": "10.0.0.101" }, { "RSIndex": 3, "Addr": "10.0.0.102" } ]}`,
+		},
+	})
+	defer server.Close()
+	client := createClientForUnit(server.Server, "baz")
+
+	tree, err := client.ShowVirtualServiceTree("1")
+	require.NoError(t, err)
+
+	assert.Equal(t, int32(1), tree.Index)
+	require.Len(t, tree.SubVS, 2)
+	assert.Equal(t, int32(2), tree.SubVS[0].Index)
+	assert.Equal(t, []RealServer{{RsIndex: 1, Address: "10.0.0.100"}}, tree.SubVS[0].Rs)
+	assert.Len(t, tree.SubVS[1].Rs, 2)
+}
@@ -0,0 +1,152 @@
+package api
+
+import (
+	"log/slog"
+	"strconv"
+)
+
+type ListTemplateResponse struct {
+	*LoadMasterResponse
+	Templates []TemplateInfo `json:"template"`
+}
+
+type TemplateInfo struct {
+	Name    string `json:"name"`
+	Comment string `json:"comment,omitempty"`
+}
+
+type VirtualServiceFromTemplateResponse struct {
+	*LoadMasterResponse
+	VirtualService *VirtualService
+	SubVS          []SubVirtualService
+}
+
+// UploadTemplate installs a template file on the LoadMaster.
+// The data argument is the base64 encoded content of the template file.
+func (c *Client) UploadTemplate(data string) (*LoadMasterResponse, error) {
+	slog.Debug("Uploading template")
+	payload := struct {
+		*LoadMasterRequest
+		Data string `json:"data"`
+	}{
+		LoadMasterRequest: &LoadMasterRequest{
+			Command: "uploadtemplate",
+		},
+		Data: data,
+	}
+
+	response, err := sendRequest(c, payload, LoadMasterResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (c *Client) ListTemplate() (*ListTemplateResponse, error) {
+	slog.Debug("Listing templates")
+	payload := struct {
+		*LoadMasterRequest
+	}{
+		&LoadMasterRequest{
+			Command: "listtemplates",
+		},
+	}
+
+	response, err := sendRequest(c, payload, ListTemplateResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (c *Client) DeleteTemplate(name string) (*LoadMasterResponse, error) {
+	slog.Debug("Deleting template", "name", name)
+	payload := struct {
+		*LoadMasterRequest
+		Name string `json:"name"`
+	}{
+		LoadMasterRequest: &LoadMasterRequest{
+			Command: "deltemplate",
+		},
+		Name: name,
+	}
+
+	response, err := sendRequest(c, payload, LoadMasterResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// ExportVirtualServiceTemplate exports an existing virtual service as a template.
+// The returned data contains the base64 encoded template file.
+func (c *Client) ExportVirtualServiceTemplate(vs_identifier string) (*LoadMasterDataResponse, error) {
+	slog.Debug("Exporting virtual service as template", "vs_identifier", vs_identifier)
+	payload := struct {
+		*LoadMasterRequest
+		VS string `json:"vs"`
+	}{
+		LoadMasterRequest: &LoadMasterRequest{
+			Command: "exportvstmplt",
+		},
+		VS: vs_identifier,
+	}
+
+	response, err := sendRequest(c, payload, LoadMasterDataResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// AddVirtualServiceFromTemplate creates a virtual service from an installed template.
+// The address, port and protocol override the values stored in the template.
+// The created parent and all sub virtual services defined by the template are returned.
+func (c *Client) AddVirtualServiceFromTemplate(template string, address string, port string, protocol string) (*VirtualServiceFromTemplateResponse, error) {
+	slog.Debug("Adding virtual service from template", "template", template, "address", address, "port", port, "protocol", protocol)
+	payload := struct {
+		*LoadMasterRequest
+		VS       string `json:"vs"`
+		Port     string `json:"port"`
+		Protocol string `json:"prot"`
+		Template string `json:"template"`
+	}{
+		LoadMasterRequest: &LoadMasterRequest{
+			Command: "addvs",
+		},
+		VS:       address,
+		Port:     port,
+		Protocol: protocol,
+		Template: template,
+	}
+
+	response, err := sendRequest(c, payload, VirtualServiceResponse{})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &VirtualServiceFromTemplateResponse{
+		LoadMasterResponse: response.LoadMasterResponse,
+		VirtualService:     response.VirtualService,
+	}
+
+	if response.VirtualService == nil || response.VirtualServiceParameters == nil || response.VirtualServiceParametersRealServers == nil {
+		return result, nil
+	}
+
+	for _, subvs := range response.SubVS {
+		sub_response, err := c.ShowSubVirtualService(strconv.Itoa(int(subvs.VSIndex)))
+		if err != nil {
+			return nil, err
+		}
+		if sub_response.SubVirtualService != nil {
+			result.SubVS = append(result.SubVS, *sub_response.SubVirtualService)
+		}
+	}
+
+	return result, nil
+}
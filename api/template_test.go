@@ -0,0 +1,74 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_ListTemplate(t *testing.T) {
+	testCases := []struct {
+		name         string
+		response     string
+		responseCode int
+		want         *ListTemplateResponse
+		wantErr      bool
+	}{
+		{"success response", `{"code": 200, "message": "OK", "status": "success", "template": [ { "name": "Exchange", "comment": "HTTPS Offloaded" } ]}`, 200, &ListTemplateResponse{LoadMasterResponse: &LoadMasterResponse{Code: 200, Message: "OK", Status: "success"}, Templates: []TemplateInfo{{Name: "Exchange", Comment: "HTTPS Offloaded"}}}, false},
+		{"fail response", `{"code": 400, "message": "NOK", "message": "error"}`, 400, nil, true},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+				rw.WriteHeader(tt.responseCode)
+				_, err := rw.Write([]byte(tt.response))
+				if err != nil {
+					fmt.Printf("Write failed: %v", err)
+				}
+			}))
+
+			defer server.Close()
+			client := createClientForUnit(server, "baz")
+
+			rs, err := client.ListTemplate()
+
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Client.ListTemplate() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(rs, tt.want) {
+				t.Errorf("Client.ListTemplate() = %v, want %v", rs, tt.want)
+			}
+		})
+	}
+}
+
+func TestClient_AddVirtualServiceFromTemplate(t *testing.T) {
+	server := createCommandServer(map[string][]string{
+		"addvs":  {`{"code": 200, "status": "ok", "Index": 1, "VSAddress": "10.0.0.4", "VSPort": "443", "Protocol": "tcp", "SubVS": [ { "VSIndex": 2 }, { "VSIndex": 3 } ]}`},
+		"showvs": {`{"code": 200, "status": "ok", "Index": 2, "NickName": "owa"}`, `{"code": 200, "status": "ok", "Index": 3, "NickName": "ecp"}`},
+	})
+	defer server.Close()
+	client := createClientForUnit(server.Server, "baz")
+
+	rs, err := client.AddVirtualServiceFromTemplate("Exchange", "10.0.0.4", "443", "tcp")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"addvs", "showvs", "showvs"}, server.Commands)
+	assert.Equal(t, "Exchange", server.Requests[0]["template"])
+	assert.Equal(t, "10.0.0.4", server.Requests[0]["vs"])
+	assert.Equal(t, "2", server.Requests[1]["vs"])
+	assert.Equal(t, "3", server.Requests[2]["vs"])
+
+	assert.Equal(t, int32(1), rs.VirtualService.Index)
+	require.Len(t, rs.SubVS, 2)
+	assert.Equal(t, int32(2), rs.SubVS[0].Index)
+	assert.Equal(t, "owa", rs.SubVS[0].NickName)
+	assert.Equal(t, int32(3), rs.SubVS[1].Index)
+	assert.Equal(t, "ecp", rs.SubVS[1].NickName)
+}
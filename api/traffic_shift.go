@@ -0,0 +1,239 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+)
+
+type TrafficShiftTarget struct {
+	Address string
+	Port    int32
+}
+
+type TrafficShiftOptions struct {
+	// Steps is the number of weight changes used to move all traffic to the target group.
+	Steps    int
+	Interval time.Duration
+	// TotalWeight is distributed between both groups, defaults to 1000.
+	TotalWeight int32
+	// MaxUnhealthy is the number of real servers receiving traffic which may be down before the shift is aborted.
+	MaxUnhealthy int
+	// MaxErrorRate enables the error check if positive. The shift is aborted if the share of failed connections
+	// of the virtual service since the previous step exceeds it.
+	MaxErrorRate float64
+	// ErrorRate optionally overrides the error rate read from the statistics of the LoadMaster, for example
+	// with the rate of an external monitoring system.
+	ErrorRate func(ctx context.Context) (float64, error)
+}
+
+type TrafficShiftStep struct {
+	FromPercent int
+	ToPercent   int
+}
+
+type TrafficShiftReport struct {
+	Steps      []TrafficShiftStep
+	RolledBack bool
+}
+
+func (o TrafficShiftOptions) totalWeight() int32 {
+	if o.TotalWeight <= 0 {
+		return 1000
+	}
+
+	return o.TotalWeight
+}
+
+// trafficShiftWeight returns the weight of each real server of a group receiving percent of the traffic.
+// Groups without traffic are disabled as the LoadMaster does not accept a weight of 0.
+func trafficShiftWeight(total int32, percent int, members int) RealServerParameters {
+	if percent <= 0 {
+		disabled := false
+		return RealServerParameters{Enable: &disabled}
+	}
+
+	enabled := true
+	weight := int32(int(total) * percent / 100 / members)
+
+	return RealServerParameters{Weight: max(weight, 1), Enable: &enabled}
+}
+
+// ShiftTraffic moves the traffic of a virtual service step by step from one group of real servers to another.
+// After every step it waits for the interval and checks the health of the real servers receiving traffic and,
+// if MaxErrorRate is set, the error rate of the virtual service. If a threshold is breached, the original weights
+// are restored.
+func (c *Client) ShiftTraffic(ctx context.Context, vs_identifier string, from []TrafficShiftTarget, to []TrafficShiftTarget, options TrafficShiftOptions) (*TrafficShiftReport, error) {
+	slog.Debug("Shifting traffic", "vs_identifier", vs_identifier, "steps", options.Steps)
+
+	if len(from) == 0 || len(to) == 0 {
+		return nil, fmt.Errorf("both real server groups must contain at least one real server")
+	}
+	if options.Steps < 1 {
+		return nil, fmt.Errorf("at least one step is required")
+	}
+
+	initial, err := c.ListRealServer(vs_identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	from_rs, err := trafficShiftGroup(initial.Rs, from)
+	if err != nil {
+		return nil, err
+	}
+	to_rs, err := trafficShiftGroup(initial.Rs, to)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.MaxErrorRate > 0 && options.ErrorRate == nil {
+		options.ErrorRate, err = c.virtualServiceErrorRate(vs_identifier)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	report := &TrafficShiftReport{}
+	var undo rollback
+	// Undo runs in reverse, so the source group is restored before the target group is reverted.
+	for _, rs := range append(slices.Clone(to_rs), from_rs...) {
+		enabled := rs.Enable == nil || *rs.Enable
+		undo.push(func() error {
+			_, err := c.ModifyRealServer(vs_identifier, rs.identifier(), RealServerParameters{Weight: rs.Weight, Enable: &enabled})
+			return err
+		})
+	}
+	abort := func(err error) (*TrafficShiftReport, error) {
+		report.RolledBack = true
+		return report, undo.run(err)
+	}
+
+	for step := 1; step <= options.Steps; step++ {
+		to_percent := 100 * step / options.Steps
+		current := TrafficShiftStep{FromPercent: 100 - to_percent, ToPercent: to_percent}
+
+		// Raise the target group first so the virtual service never runs without enabled real servers.
+		groups := []struct {
+			members []RealServer
+			percent int
+		}{{to_rs, current.ToPercent}, {from_rs, current.FromPercent}}
+		for _, group := range groups {
+			parameters := trafficShiftWeight(options.totalWeight(), group.percent, len(group.members))
+			for _, rs := range group.members {
+				_, err := c.ModifyRealServer(vs_identifier, rs.identifier(), parameters)
+				if err != nil {
+					return abort(err)
+				}
+			}
+		}
+		report.Steps = append(report.Steps, current)
+
+		select {
+		case <-ctx.Done():
+			return abort(ctx.Err())
+		case <-time.After(options.Interval):
+		}
+
+		if err := c.checkTrafficShift(ctx, vs_identifier, from_rs, to_rs, current, options); err != nil {
+			return abort(err)
+		}
+	}
+
+	return report, nil
+}
+
+func (c *Client) checkTrafficShift(ctx context.Context, vs_identifier string, from_rs []RealServer, to_rs []RealServer, step TrafficShiftStep, options TrafficShiftOptions) error {
+	response, err := c.ListRealServer(vs_identifier)
+	if err != nil {
+		return err
+	}
+
+	serving := map[int32]bool{}
+	if step.FromPercent > 0 {
+		for _, rs := range from_rs {
+			serving[rs.RsIndex] = true
+		}
+	}
+	if step.ToPercent > 0 {
+		for _, rs := range to_rs {
+			serving[rs.RsIndex] = true
+		}
+	}
+
+	unhealthy := 0
+	for _, rs := range response.Rs {
+		if serving[rs.RsIndex] && rs.State() != RealServerStateUp {
+			unhealthy++
+		}
+	}
+	if unhealthy > options.MaxUnhealthy {
+		return fmt.Errorf("%d real servers are not up at %d/%d, allowed are %d", unhealthy, step.FromPercent, step.ToPercent, options.MaxUnhealthy)
+	}
+
+	if options.MaxErrorRate > 0 {
+		rate, err := options.ErrorRate(ctx)
+		if err != nil {
+			return err
+		}
+		if rate > options.MaxErrorRate {
+			return fmt.Errorf("error rate %f exceeds %f at %d/%d", rate, options.MaxErrorRate, step.FromPercent, step.ToPercent)
+		}
+	}
+
+	return nil
+}
+
+// virtualServiceErrorRate returns a function which reports the share of failed connections of the virtual service
+// since its previous call, starting from the statistics read now.
+func (c *Client) virtualServiceErrorRate(vs_identifier string) (func(ctx context.Context) (float64, error), error) {
+	vs, err := c.ShowVirtualService(vs_identifier)
+	if err != nil {
+		return nil, err
+	}
+	if vs.VirtualService == nil {
+		return nil, fmt.Errorf("virtual service %s not found", vs_identifier)
+	}
+	index := vs.Index
+
+	previous, err := c.ShowVirtualServiceStatistics(index)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(ctx context.Context) (float64, error) {
+		current, err := c.ShowVirtualServiceStatistics(index)
+		if err != nil {
+			return 0, err
+		}
+		connections := current.TotalConns - previous.TotalConns
+		errors := current.ErrorConns - previous.ErrorConns
+		previous = current
+		if connections <= 0 {
+			return 0, nil
+		}
+
+		return float64(errors) / float64(connections), nil
+	}, nil
+}
+
+func trafficShiftGroup(current []RealServer, targets []TrafficShiftTarget) ([]RealServer, error) {
+	group := make([]RealServer, 0, len(targets))
+	for _, target := range targets {
+		found := false
+		for _, rs := range current {
+			if rs.Address == target.Address && rs.Port == target.Port {
+				group = append(group, rs)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("real server %s not found", realServerKey(target.Address, target.Port))
+		}
+	}
+
+	return group, nil
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_ShiftTraffic(t *testing.T) {
+	showvs := `{"code": 200, "status": "ok", "Index": 1, "Rs": [ { "RSIndex": 1, "Addr": "10.0.0.100", "Port": 80, "Weight": 1000, "Status": "Up" }, { "RSIndex": 2, "Addr": "10.0.0.101", "Port": 80, "Weight": 1000, "Status": "Up", "Enable": false } ]}`
+	blue := []TrafficShiftTarget{{Address: "10.0.0.100", Port: 80}}
+	green := []TrafficShiftTarget{{Address: "10.0.0.101", Port: 80}}
+	healthy := `{"code": 200, "status": "ok", "Index": 1, "Rs": [ { "RSIndex": 1, "Status": "Up" }, { "RSIndex": 2, "Status": "Up" } ]}`
+
+	t.Run("success", func(t *testing.T) {
+		server := createCommandServer(map[string][]string{
+			"showvs": {showvs, healthy},
+			"modrs":  {`{"code": 200, "status": "ok"}`},
+		})
+		defer server.Close()
+		client := createClientForUnit(server.Server, "baz")
+
+		report, err := client.ShiftTraffic(context.Background(), "1", blue, green, TrafficShiftOptions{Steps: 2})
+		require.NoError(t, err)
+
+		assert.Equal(t, []TrafficShiftStep{{50, 50}, {0, 100}}, report.Steps)
+		assert.False(t, report.RolledBack)
+		assert.Equal(t, []string{"showvs", "modrs", "modrs", "showvs", "modrs", "modrs", "showvs"}, server.Commands)
+		assert.Equal(t, "!2", server.Requests[1]["rs"])
+		assert.Equal(t, float64(500), server.Requests[1]["Weight"])
+		assert.Equal(t, true, server.Requests[1]["Enable"])
+		assert.Equal(t, "!1", server.Requests[5]["rs"])
+		assert.Equal(t, false, server.Requests[5]["Enable"])
+	})
+
+	t.Run("rollback on unhealthy real server", func(t *testing.T) {
+		server := createCommandServer(map[string][]string{
+			"showvs": {showvs, `{"code": 200, "status": "ok", "Index": 1, "Rs": [ { "RSIndex": 1, "Status": "Up" }, { "RSIndex": 2, "Status": "Down" } ]}`},
+			"modrs":  {`{"code": 200, "status": "ok"}`},
+		})
+		defer server.Close()
+		client := createClientForUnit(server.Server, "baz")
+
+		report, err := client.ShiftTraffic(context.Background(), "1", blue, green, TrafficShiftOptions{Steps: 4})
+		require.Error(t, err)
+
+		assert.True(t, report.RolledBack)
+		assert.Len(t, report.Steps, 1)
+		last := server.Requests[len(server.Requests)-2:]
+		assert.Equal(t, "!1", last[0]["rs"])
+		assert.Equal(t, float64(1000), last[0]["Weight"])
+		assert.Equal(t, true, last[0]["Enable"])
+		assert.Equal(t, "!2", last[1]["rs"])
+		assert.Equal(t, false, last[1]["Enable"])
+	})
+
+	t.Run("rollback on error rate", func(t *testing.T) {
+		server := createCommandServer(map[string][]string{
+			"showvs": {showvs, healthy},
+			"modrs":  {`{"code": 200, "status": "ok"}`},
+		})
+		defer server.Close()
+		client := createClientForUnit(server.Server, "baz")
+
+		report, err := client.ShiftTraffic(context.Background(), "1", blue, green, TrafficShiftOptions{
+			Steps:        2,
+			ErrorRate:    func(ctx context.Context) (float64, error) { return 0.2, nil },
+			MaxErrorRate: 0.05,
+		})
+		require.Error(t, err)
+		assert.True(t, report.RolledBack)
+	})
+
+	t.Run("rollback on error statistics", func(t *testing.T) {
+		server := createCommandServer(map[string][]string{
+			"showvs": {showvs, showvs, healthy},
+			"stats": {
+				`{"code": 200, "status": "ok", "Vs": [ { "Index": 1, "TotalConns": 100, "ErrorConns": 1 } ]}`,
+				`{"code": 200, "status": "ok", "Vs": [ { "Index": 1, "TotalConns": 200, "ErrorConns": 21 } ]}`,
+			},
+			"modrs": {`{"code": 200, "status": "ok"}`},
+		})
+		defer server.Close()
+		client := createClientForUnit(server.Server, "baz")
+
+		report, err := client.ShiftTraffic(context.Background(), "1", blue, green, TrafficShiftOptions{Steps: 2, MaxErrorRate: 0.05})
+		require.ErrorContains(t, err, "error rate")
+		assert.True(t, report.RolledBack)
+		assert.Len(t, report.Steps, 1)
+		assert.Equal(t, []string{"showvs", "showvs", "stats", "modrs", "modrs", "showvs", "stats", "modrs", "modrs"}, server.Commands)
+	})
+
+	t.Run("unknown real server", func(t *testing.T) {
+		server := createCommandServer(map[string][]string{"showvs": {showvs}})
+		defer server.Close()
+		client := createClientForUnit(server.Server, "baz")
+
+		_, err := client.ShiftTraffic(context.Background(), "1", blue, []TrafficShiftTarget{{Address: "10.0.0.200", Port: 80}}, TrafficShiftOptions{Steps: 1})
+		assert.Error(t, err)
+	})
+}
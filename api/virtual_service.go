@@ -3,14 +3,15 @@ package api
 import "log/slog"
 
 type VirtualService struct {
-	Index          int32    `json:"Index"`
-	Protocol       string   `json:"Protocol"`
-	Address        string   `json:"VSAddress"`
-	Port           string   `json:"VSPort"`
-	MasterVS       *int32   `json:"MasterVS"`
-	MasterVSID     int32    `json:"MasterVSID,omitempty"`
-	MatchRules     []string `json:"MatchRules,omitempty"`
-	MatchBodyRules []string `json:"MatchBodyRules,omitempty"`
+	Index          int32        `json:"Index"`
+	Protocol       string       `json:"Protocol"`
+	Address        string       `json:"VSAddress"`
+	Port           string       `json:"VSPort"`
+	MasterVS       *int32       `json:"MasterVS"`
+	MasterVSID     int32        `json:"MasterVSID,omitempty"`
+	MatchRules     []string     `json:"MatchRules,omitempty"`
+	MatchBodyRules []string     `json:"MatchBodyRules,omitempty"`
+	Rs             []RealServer `json:"Rs,omitempty"`
 	*VirtualServiceParameters
 }
 
@@ -55,23 +56,23 @@ type VirtualServiceParametersStandardOptions struct {
 }
 
 type VirtualServiceParametersSSLProperties struct {
-	CertFile              string `json:"CertFile,omitempty"`
-	Ciphers               string `json:"Ciphers,omitempty"`
-	CipherSet             string `json:"CipherSet,omitempty"`
-	Tls13CipherSet        string `json:"Tls13CipherSet,omitempty"`
-	ClientCert            *int32 `json:"ClientCert,omitempty"`
-	PassCipher            *bool  `json:"PassCipher,omitempty"`
-	SSLReencrypt          *bool  `json:"SSLReencrypt,omitempty"`
-	PassSNI               *bool  `json:"PassSNI,omitempty"`
-	SSLReverse            *bool  `json:"SSLReverse,omitempty"`
-	SSLRewrite            string `json:"SSLRewrite,omitempty"`
-	ReverseSNIHostname    string `json:"ReverseSNIHostname,omitempty"`
-	SecurityHeaderOptions *int32 `json:"SecurityHeaderOptions,omitempty"`
-	SSLAcceleration       *bool  `json:"SSLAcceleration,omitempty"`
-	OCSPVerify            *bool  `json:"OCSPVerify,omitempty"`
-	TLSType               string `json:"TLSType,omitempty"`
-	NeedHostName          *bool  `json:"NeedHostName,omitempty"`
-	IntermediateCerts     string `json:"IntermediateCerts,omitempty"`
+	CertFile              string          `json:"CertFile,omitempty"`
+	Ciphers               string          `json:"Ciphers,omitempty"`
+	CipherSet             string          `json:"CipherSet,omitempty"`
+	Tls13CipherSet        string          `json:"Tls13CipherSet,omitempty"`
+	ClientCert            *ClientCertMode `json:"ClientCert,omitempty"`
+	PassCipher            *bool           `json:"PassCipher,omitempty"`
+	SSLReencrypt          *bool           `json:"SSLReencrypt,omitempty"`
+	PassSNI               *bool           `json:"PassSNI,omitempty"`
+	SSLReverse            *bool           `json:"SSLReverse,omitempty"`
+	SSLRewrite            string          `json:"SSLRewrite,omitempty"`
+	ReverseSNIHostname    string          `json:"ReverseSNIHostname,omitempty"`
+	SecurityHeaderOptions *int32          `json:"SecurityHeaderOptions,omitempty"`
+	SSLAcceleration       *bool           `json:"SSLAcceleration,omitempty"`
+	OCSPVerify            *bool           `json:"OCSPVerify,omitempty"`
+	TLSType               string          `json:"TLSType,omitempty"`
+	NeedHostName          *bool           `json:"NeedHostName,omitempty"`
+	IntermediateCerts     string          `json:"IntermediateCerts,omitempty"`
 }
 
 type VirtualServiceParametersAdvancedProperties struct {
@@ -185,6 +186,7 @@ type VirtualServiceParametersRealServers struct {
 	RSRulePrecedencePos  *int32              `json:"RSRulePrecedencePos,omitempty"`
 	EnhancedHealthchecks *bool               `json:"EnhancedHealthchecks,omitempty"`
 	RsMinimum            *int32              `json:"RsMinimum,omitempty"`
+	ContentSwitching     *bool               `json:"ContentSwitching,omitempty"`
 }
 
 type VirtualServiceParametersMiscellaneous struct {